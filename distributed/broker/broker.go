@@ -37,6 +37,10 @@ type Broker struct {
 	quit        chan bool
 	serverList  []Server
 	nodes       int
+	// 每AliveEveryTurns回合记录一次的存活细胞数量，只保留控制器还没有取回的报告
+	aliveReports []stubs.AliveCellsCountResponse
+	// 已经被控制器取回并丢弃的报告数量
+	aliveReportsTrimmed int
 }
 
 func handleError(err error) {
@@ -44,6 +48,19 @@ func handleError(err error) {
 	os.Exit(1)
 }
 
+// countAlive 返回世界中存活细胞的数量
+func countAlive(height, width int, world [][]uint8) int {
+	aliveCellsCount := 0
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			if world[y][x] == 255 {
+				aliveCellsCount++
+			}
+		}
+	}
+	return aliveCellsCount
+}
+
 func copyWorld(height, width int, world [][]uint8) [][]uint8 {
 	newWorld := make([][]uint8, height)
 	for i := range newWorld {
@@ -74,6 +91,8 @@ func (b *Broker) RunGol(req stubs.RunGolRequest, res *stubs.RunGolResponse) (err
 	b.worldWidth = req.GolBoard.Width
 	b.worldHeight = req.GolBoard.Height
 	b.world = req.GolBoard.World
+	b.aliveReports = nil
+	b.aliveReportsTrimmed = 0
 	if len(b.serverList) == 0 {
 		b.serverList = make([]Server, 0, Nodes)
 		connectedNode := 0
@@ -146,6 +165,13 @@ func (b *Broker) RunGol(req stubs.RunGolRequest, res *stubs.RunGolResponse) (err
 		b.processLock.Lock()
 		b.world = world
 		b.currentTurn = turn + 1
+		// 按回合报告时，记录完成该回合时的存活细胞数量
+		if req.AliveEveryTurns > 0 && b.currentTurn%req.AliveEveryTurns == 0 {
+			b.aliveReports = append(b.aliveReports, stubs.AliveCellsCountResponse{
+				CurrentTurn: b.currentTurn,
+				Count:       countAlive(b.worldHeight, b.worldWidth, world),
+			})
+		}
 		b.processLock.Unlock()
 
 		select {
@@ -163,21 +189,29 @@ func (b *Broker) RunGol(req stubs.RunGolRequest, res *stubs.RunGolResponse) (err
 
 // CountAliveCells 补充注释
 func (b *Broker) CountAliveCells(_ stubs.AliveCellsCountRequest, res *stubs.AliveCellsCountResponse) (err error) {
-	aliveCellsCount := 0
 	b.processLock.Lock()
-	for x := 0; x < b.worldWidth; x++ {
-		for y := 0; y < b.worldHeight; y++ {
-			if b.world[y][x] == 255 {
-				aliveCellsCount++
-			}
-		}
-	}
-	res.Count = aliveCellsCount
+	res.Count = countAlive(b.worldHeight, b.worldWidth, b.world)
 	res.CurrentTurn = b.currentTurn
 	b.processLock.Unlock()
 	return
 }
 
+// AliveReports 返回从第req.From个开始，按回合记录的存活细胞数量，并丢弃控制器已经取回的第req.From个之前的报告
+func (b *Broker) AliveReports(req stubs.AliveReportsRequest, res *stubs.AliveReportsResponse) (err error) {
+	b.processLock.Lock()
+	received := req.From - b.aliveReportsTrimmed
+	if received > len(b.aliveReports) {
+		received = len(b.aliveReports)
+	}
+	if received > 0 {
+		b.aliveReports = append([]stubs.AliveCellsCountResponse(nil), b.aliveReports[received:]...)
+		b.aliveReportsTrimmed += received
+	}
+	res.Reports = append(res.Reports, b.aliveReports...)
+	b.processLock.Unlock()
+	return
+}
+
 func (b *Broker) GetWorld(_ stubs.CurrentWorldRequest, res *stubs.CurrentWorldResponse) (err error) {
 	b.processLock.Lock()
 	res.GolBoard = stubs.GolBoard{World: b.world, CurrentTurn: b.currentTurn, Width: b.worldWidth, Height: b.worldHeight}
@@ -10,6 +10,9 @@ import (
 	"uk.ac.bris.cs/gameoflife/util"
 )
 
+// aliveReportsPoll is how often the broker is polled for turn based AliveCellsCount reports.
+const aliveReportsPoll = 100 * time.Millisecond
+
 type distributorChannels struct {
 	events     chan<- Event
 	ioCommand  chan<- ioCommand
@@ -91,16 +94,22 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 	if p.Turns > 0 {
 		finalTurnFinish := make(chan stubs.GolBoard)
 		countFinish := make(chan bool)
+		aliveReports := make(chan []stubs.AliveCellsCountResponse)
 		quit := make(chan bool)
 
 		golBoard := stubs.GolBoard{World: world, Width: p.ImageWidth, Height: p.ImageHeight}
-		req := stubs.RunGolRequest{GolBoard: golBoard, Turns: p.Turns, Threads: p.Threads}
+		req := stubs.RunGolRequest{GolBoard: golBoard, Turns: p.Turns, Threads: p.Threads, AliveEveryTurns: p.AliveEveryTurns}
 		var countReq stubs.AliveCellsCountRequest
 		var res stubs.RunGolResponse
 		var countRes stubs.AliveCellsCountResponse
 		var worldReq stubs.CurrentWorldRequest
 		var worldRes stubs.CurrentWorldResponse
-		ticker := time.NewTicker(2 * time.Second)
+		// 按回合报告时，broker记录每个报告，这里只需要定期取回
+		interval := p.AliveInterval
+		if p.AliveEveryTurns > 0 {
+			interval = aliveReportsPoll
+		}
+		ticker := time.NewTicker(interval)
 		// 调用服务器运行所有的回合
 		go func() {
 			runErr := broker.Call("Broker.RunGol", req, &res)
@@ -109,15 +118,54 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 			}
 		}()
 
-		// 调用服务器每隔两秒返回存活细胞数量
-		go func() {
-			for {
-				<-ticker.C
-				countErr := broker.Call("Broker.CountAliveCells", countReq, &countRes)
-				dialError(countErr, c)
-				countFinish <- true
-			}
-		}()
+		// 运行结束时关闭done，停止定期查询的子线程
+		done := make(chan bool)
+		// 定期取回的子线程结束时，通过polled交回已经取回的报告数量
+		polled := make(chan int, 1)
+		if p.AliveEveryTurns > 0 {
+			// 定期取回broker按回合记录的存活细胞数量，取回的报告交给主循环后broker才会丢弃它们
+			go func() {
+				received := 0
+				for {
+					select {
+					case <-done:
+						polled <- received
+						return
+					case <-ticker.C:
+					}
+					var reportsRes stubs.AliveReportsResponse
+					reportsErr := broker.Call("Broker.AliveReports", stubs.AliveReportsRequest{From: received}, &reportsRes)
+					dialError(reportsErr, c)
+					if len(reportsRes.Reports) > 0 {
+						select {
+						case aliveReports <- reportsRes.Reports:
+							received += len(reportsRes.Reports)
+						case <-done:
+							polled <- received
+							return
+						}
+					}
+				}
+			}()
+		} else {
+			// 调用服务器每隔AliveInterval返回存活细胞数量
+			go func() {
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+					}
+					countErr := broker.Call("Broker.CountAliveCells", countReq, &countRes)
+					dialError(countErr, c)
+					select {
+					case countFinish <- true:
+					case <-done:
+						return
+					}
+				}
+			}()
+		}
 
 		// 按键控制器
 		go func() {
@@ -149,7 +197,7 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 							res := stubs.PauseResponse{}
 							pauseErr = broker.Call("Broker.Pause", stubs.PauseRequest{}, &res)
 							dialError(pauseErr, c)
-							ticker.Reset(2 * time.Second) // 重新开始ticker计时
+							ticker.Reset(interval) // 重新开始ticker计时
 							paused = false
 							c.events <- StateChange{CompletedTurns: res.CurrentTurn, NewState: Executing}
 						}
@@ -162,18 +210,43 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 			}
 		}()
 
+		// 最后一次报告的回合，避免重复报告
+		reportedTurn := 0
 		finishFlag := false
 		for {
 			select {
 			case board := <-finalTurnFinish:
 				finishFlag = true
+				ticker.Stop()
+				close(done)
 				turn = board.CurrentTurn
+				if p.AliveEveryTurns > 0 {
+					// 补发还没有取回的按回合报告
+					var reportsRes stubs.AliveReportsResponse
+					reportsErr := broker.Call("Broker.AliveReports", stubs.AliveReportsRequest{From: <-polled}, &reportsRes)
+					dialError(reportsErr, c)
+					for _, report := range reportsRes.Reports {
+						if report.CurrentTurn > reportedTurn {
+							reportedTurn = report.CurrentTurn
+							c.events <- AliveCellsCount{CompletedTurns: report.CurrentTurn, CellsCount: report.Count}
+						}
+					}
+				}
 				outputPGM(c, p, board.CurrentTurn, board.World)
 				c.events <- FinalTurnComplete{board.CurrentTurn, findAliveCells(p, board.World)}
 			case <-quit:
 				finishFlag = true
+				ticker.Stop()
+				close(done)
 			case <-countFinish:
 				c.events <- AliveCellsCount{CompletedTurns: countRes.CurrentTurn, CellsCount: countRes.Count}
+			case reports := <-aliveReports:
+				for _, report := range reports {
+					if report.CurrentTurn > reportedTurn {
+						reportedTurn = report.CurrentTurn
+						c.events <- AliveCellsCount{CompletedTurns: report.CurrentTurn, CellsCount: report.Count}
+					}
+				}
 			}
 			if finishFlag {
 				break
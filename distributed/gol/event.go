@@ -15,7 +15,7 @@ type Event interface {
 }
 
 // AliveCellsCount is an Event notifying the user about the number of currently alive cells.
-// This Event should be sent every 2s.
+// This Event should be sent every 2s, or as configured by Params.AliveInterval and Params.AliveEveryTurns.
 type AliveCellsCount struct { // implements Event
 	CompletedTurns int
 	CellsCount     int
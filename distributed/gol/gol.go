@@ -1,15 +1,29 @@
 package gol
 
+import "time"
+
+// defaultAliveInterval is the interval between AliveCellsCount events when Params.AliveInterval is not set.
+const defaultAliveInterval = 2 * time.Second
+
 // Params provides the details of how to run the Game of Life and which image to load.
 type Params struct {
 	Turns       int
 	Threads     int
 	ImageWidth  int
 	ImageHeight int
+
+	// AliveInterval is the interval between AliveCellsCount events. Defaults to 2s.
+	AliveInterval time.Duration
+	// AliveEveryTurns sends AliveCellsCount events every AliveEveryTurns turns instead of every AliveInterval,
+	// so the reports are deterministic. 0 reports by time.
+	AliveEveryTurns int
 }
 
 // Run starts the processing of Game of Life. It should initialise channels and goroutines.
 func Run(p Params, events chan<- Event, keyPresses <-chan rune) {
+	if p.AliveInterval <= 0 {
+		p.AliveInterval = defaultAliveInterval
+	}
 	ioCommand := make(chan ioCommand)
 	ioIdle := make(chan bool)
 	ioFilename := make(chan string)
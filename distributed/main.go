@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"runtime"
+	"time"
 
 	"uk.ac.bris.cs/gameoflife/gol"
 	"uk.ac.bris.cs/gameoflife/sdl"
@@ -38,6 +39,18 @@ func main() {
 		10000000000,
 		"Specify the number of turns to process. Defaults to 10000000000.")
 
+	flag.DurationVar(
+		&params.AliveInterval,
+		"alive-interval",
+		2*time.Second,
+		"Specify the interval between alive cell count reports. Defaults to 2s.")
+
+	flag.IntVar(
+		&params.AliveEveryTurns,
+		"alive-every",
+		0,
+		"Report the alive cell count every n turns instead of at an interval. Defaults to 0, which reports at an interval.")
+
 	noVis := flag.Bool(
 		"noVis",
 		false,
@@ -12,9 +12,10 @@ type GolBoard struct {
 // These will use by distributor
 
 type RunGolRequest struct {
-	GolBoard GolBoard
-	Threads  int
-	Turns    int
+	GolBoard        GolBoard
+	Threads         int
+	Turns           int
+	AliveEveryTurns int
 }
 type RunGolResponse struct {
 	GolBoard GolBoard
@@ -33,6 +34,14 @@ type AliveCellsCountResponse struct {
 	Count       int
 }
 
+// AliveReportsRequest 请求从第From个开始的按回合记录的存活细胞数量，From之前的报告已经取回，broker可以丢弃
+type AliveReportsRequest struct {
+	From int
+}
+type AliveReportsResponse struct {
+	Reports []AliveCellsCountResponse
+}
+
 // These will use by broker
 
 type ServerAddress struct {
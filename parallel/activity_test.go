@@ -0,0 +1,64 @@
+package main
+
+import (
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestActivity tests the activity images of a blinker: its ends flip every turn,
+// its centre never changes, and the rest of the world stays settled.
+func TestActivity(t *testing.T) {
+	p := gol.Params{
+		Turns:       10,
+		Threads:     4,
+		ImageWidth:  16,
+		ImageHeight: 16,
+		InputDir:    t.TempDir(),
+		OutputDir:   t.TempDir(),
+		Activity:    true,
+	}
+	frame := make([]byte, p.ImageWidth*p.ImageHeight)
+	for _, cell := range pictureCells([]string{"OOO"}, 6, 8) {
+		frame[cell.Y*p.ImageWidth+cell.X] = 255
+	}
+	err := ioutil.WriteFile(filepath.Join(p.InputDir, "16x16.pgm"), append([]byte("P5\n16 16\n255\n"), frame...), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	for range events {
+	}
+
+	white := color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	black := color.RGBA{0x00, 0x00, 0x00, 0xFF}
+	for _, name := range []string{"16x16x10-flips", "16x16x10-age"} {
+		file, err := os.Open(filepath.Join(p.OutputDir, name+".png"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(file)
+		_ = file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[[2]int]color.RGBA{
+			{6, 8}: white, // an end, flipping every turn
+			{7, 9}: white,
+			{7, 8}: black, // the centre, which never changes
+			{0, 0}: black,
+		}
+		for cell, colour := range expected {
+			if actual := color.RGBAModel.Convert(img.At(cell[0], cell[1])); actual != colour {
+				t.Errorf("%v: expected %v at %v, got %v", name, colour, cell, actual)
+			}
+		}
+	}
+}
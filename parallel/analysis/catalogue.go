@@ -0,0 +1,237 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Kind is the kind of object a pattern is.
+type Kind int
+
+const (
+	Unknown Kind = iota
+	StillLife
+	Oscillator
+	Spaceship
+	// Puffer is a moving pattern that leaves debris behind, see FindPeriod.
+	Puffer
+)
+
+func (kind Kind) String() string {
+	switch kind {
+	case StillLife:
+		return "still life"
+	case Oscillator:
+		return "oscillator"
+	case Spaceship:
+		return "spaceship"
+	case Puffer:
+		return "puffer"
+	default:
+		return "unknown"
+	}
+}
+
+// Pattern is an entry of the Catalogue.
+type Pattern struct {
+	Name   string
+	Kind   Kind
+	Period int
+	// Picture draws one phase of the pattern, with 'O' for alive cells and '.' for dead cells.
+	Picture []string
+}
+
+// Catalogue lists the common objects that are recognised by Objects.
+var Catalogue = []Pattern{
+	{Name: "block", Kind: StillLife, Period: 1, Picture: []string{
+		"OO",
+		"OO",
+	}},
+	{Name: "beehive", Kind: StillLife, Period: 1, Picture: []string{
+		".OO.",
+		"O..O",
+		".OO.",
+	}},
+	{Name: "loaf", Kind: StillLife, Period: 1, Picture: []string{
+		".OO.",
+		"O..O",
+		".O.O",
+		"..O.",
+	}},
+	{Name: "boat", Kind: StillLife, Period: 1, Picture: []string{
+		"OO.",
+		"O.O",
+		".O.",
+	}},
+	{Name: "ship", Kind: StillLife, Period: 1, Picture: []string{
+		"OO.",
+		"O.O",
+		".OO",
+	}},
+	{Name: "tub", Kind: StillLife, Period: 1, Picture: []string{
+		".O.",
+		"O.O",
+		".O.",
+	}},
+	{Name: "pond", Kind: StillLife, Period: 1, Picture: []string{
+		".OO.",
+		"O..O",
+		"O..O",
+		".OO.",
+	}},
+	{Name: "barge", Kind: StillLife, Period: 1, Picture: []string{
+		".O..",
+		"O.O.",
+		".O.O",
+		"..O.",
+	}},
+	{Name: "long boat", Kind: StillLife, Period: 1, Picture: []string{
+		"OO..",
+		"O.O.",
+		".O.O",
+		"..O.",
+	}},
+	{Name: "snake", Kind: StillLife, Period: 1, Picture: []string{
+		"OO.O",
+		"O.OO",
+	}},
+	{Name: "eater", Kind: StillLife, Period: 1, Picture: []string{
+		"OO..",
+		"O.O.",
+		"..O.",
+		"..OO",
+	}},
+	{Name: "blinker", Kind: Oscillator, Period: 2, Picture: []string{
+		"OOO",
+	}},
+	{Name: "toad", Kind: Oscillator, Period: 2, Picture: []string{
+		".OOO",
+		"OOO.",
+	}},
+	{Name: "beacon", Kind: Oscillator, Period: 2, Picture: []string{
+		"OO..",
+		"OO..",
+		"..OO",
+		"..OO",
+	}},
+	{Name: "glider", Kind: Spaceship, Period: 4, Picture: []string{
+		".O.",
+		"..O",
+		"OOO",
+	}},
+	{Name: "LWSS", Kind: Spaceship, Period: 4, Picture: []string{
+		".O..O",
+		"O....",
+		"O...O",
+		"OOOO.",
+	}},
+	{Name: "MWSS", Kind: Spaceship, Period: 4, Picture: []string{
+		"...O..",
+		".O...O",
+		"O.....",
+		"O....O",
+		"OOOOO.",
+	}},
+	{Name: "HWSS", Kind: Spaceship, Period: 4, Picture: []string{
+		"...OO..",
+		".O....O",
+		"O......",
+		"O.....O",
+		"OOOOOO.",
+	}},
+}
+
+// catalogued maps the canonical key of every phase of every pattern in the Catalogue,
+// in every orientation, to its pattern.
+var catalogued = map[string]*Pattern{}
+
+func init() {
+	for i := range Catalogue {
+		pattern := &Catalogue[i]
+		cells := pictureCells(pattern.Picture)
+		for phase := 0; phase < pattern.Period; phase++ {
+			catalogued[canonicalKey(cells)] = pattern
+			cells = Step(cells)
+		}
+	}
+}
+
+// pictureCells returns the alive cells of a picture.
+func pictureCells(picture []string) []util.Cell {
+	var cells []util.Cell
+	for y, row := range picture {
+		for x, c := range row {
+			if c == 'O' {
+				cells = append(cells, util.Cell{X: x, Y: y})
+			}
+		}
+	}
+	return cells
+}
+
+// Lookup returns the pattern in the Catalogue with the same shape as cells, in any phase or orientation.
+func Lookup(cells []util.Cell) (*Pattern, bool) {
+	pattern, ok := catalogued[canonicalKey(cells)]
+	return pattern, ok
+}
+
+// transforms are the 8 rotations and reflections of the plane.
+var transforms = [8]func(c util.Cell) util.Cell{
+	func(c util.Cell) util.Cell { return util.Cell{X: c.X, Y: c.Y} },
+	func(c util.Cell) util.Cell { return util.Cell{X: -c.X, Y: c.Y} },
+	func(c util.Cell) util.Cell { return util.Cell{X: c.X, Y: -c.Y} },
+	func(c util.Cell) util.Cell { return util.Cell{X: -c.X, Y: -c.Y} },
+	func(c util.Cell) util.Cell { return util.Cell{X: c.Y, Y: c.X} },
+	func(c util.Cell) util.Cell { return util.Cell{X: -c.Y, Y: c.X} },
+	func(c util.Cell) util.Cell { return util.Cell{X: c.Y, Y: -c.X} },
+	func(c util.Cell) util.Cell { return util.Cell{X: -c.Y, Y: -c.X} },
+}
+
+// canonicalKey returns a key that is the same for every translation, rotation and reflection of cells.
+func canonicalKey(cells []util.Cell) string {
+	key := ""
+	transformed := make([]util.Cell, len(cells))
+	for i, transform := range transforms {
+		for j, cell := range cells {
+			transformed[j] = transform(cell)
+		}
+		if k := shapeKey(transformed); i == 0 || k < key {
+			key = k
+		}
+	}
+	return key
+}
+
+// shapeKey returns a key that is the same for every translation of cells. It sorts cells in place.
+func shapeKey(cells []util.Cell) string {
+	if len(cells) == 0 {
+		return ""
+	}
+	normalise(cells)
+	var key strings.Builder
+	for _, cell := range cells {
+		key.WriteString(strconv.Itoa(cell.X))
+		key.WriteByte(',')
+		key.WriteString(strconv.Itoa(cell.Y))
+		key.WriteByte(';')
+	}
+	return key.String()
+}
+
+// normalise translates cells so the smallest x and y are 0, and sorts them by row then column.
+func normalise(cells []util.Cell) {
+	bounds := cellBounds(cells)
+	for i := range cells {
+		cells[i].X -= bounds.Min.X
+		cells[i].Y -= bounds.Min.Y
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Y != cells[j].Y {
+			return cells[i].Y < cells[j].Y
+		}
+		return cells[i].X < cells[j].X
+	})
+}
@@ -0,0 +1,55 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Census counts the objects on a board by name.
+type Census struct {
+	Objects []Object
+	Counts  map[string]int
+}
+
+// TakeCensus finds the objects on a row-major frame (0 dead, 255 alive) on a torus and counts them.
+func TakeCensus(frame []uint8, width, height int) Census {
+	census := Census{Objects: Objects(frame, width, height), Counts: map[string]int{}}
+	for _, object := range census.Objects {
+		census.Counts[object.Name]++
+	}
+	return census
+}
+
+// Names returns the names of the objects in the census, the most common first.
+func (census Census) Names() []string {
+	names := make([]string, 0, len(census.Counts))
+	for name := range census.Counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if census.Counts[names[i]] != census.Counts[names[j]] {
+			return census.Counts[names[i]] > census.Counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// WriteReport writes the census as a table of object names, kinds and counts.
+func (census Census) WriteReport(w io.Writer) error {
+	kinds := map[string]Kind{}
+	cells := 0
+	for _, object := range census.Objects {
+		kinds[object.Name] = object.Kind
+		cells += len(object.Cells)
+	}
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintf(table, "%v objects, %v cells\n\n", len(census.Objects), cells)
+	_, _ = fmt.Fprintln(table, "object\tkind\tcount")
+	for _, name := range census.Names() {
+		_, _ = fmt.Fprintf(table, "%v\t%v\t%v\n", name, kinds[name], census.Counts[name])
+	}
+	return table.Flush()
+}
@@ -0,0 +1,171 @@
+// Package analysis finds and classifies the objects on a Game of Life board.
+package analysis
+
+import (
+	"fmt"
+	"image"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Object is a group of alive cells on the board.
+type Object struct {
+	// Name is the name of the pattern in the Catalogue, or "unknown-" followed by the number of cells.
+	Name   string
+	Kind   Kind
+	Period int
+	// Cells are the alive cells of the object, in board coordinates.
+	Cells []util.Cell
+	// Bounds is the smallest rectangle containing the object. When the object wraps around the
+	// edges of the board, Bounds extends past the board so that it stays a single rectangle.
+	Bounds image.Rectangle
+}
+
+// Step returns the next generation of cells on an unbounded plane.
+func Step(cells []util.Cell) []util.Cell {
+	alive := make(map[util.Cell]bool, len(cells))
+	neighbours := make(map[util.Cell]int, 9*len(cells))
+	for _, cell := range cells {
+		alive[cell] = true
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dx != 0 || dy != 0 {
+					neighbours[util.Cell{X: cell.X + dx, Y: cell.Y + dy}]++
+				}
+			}
+		}
+	}
+	var next []util.Cell
+	for cell, count := range neighbours {
+		if count == 3 || (count == 2 && alive[cell]) {
+			next = append(next, cell)
+		}
+	}
+	return next
+}
+
+// cellBounds returns the smallest rectangle containing every cell.
+func cellBounds(cells []util.Cell) image.Rectangle {
+	var bounds image.Rectangle
+	for i, cell := range cells {
+		cellRect := image.Rect(cell.X, cell.Y, cell.X+1, cell.Y+1)
+		if i == 0 {
+			bounds = cellRect
+		} else {
+			bounds = bounds.Union(cellRect)
+		}
+	}
+	return bounds
+}
+
+// Components labels the 8-connected components of the alive cells in a row-major frame
+// (0 dead, 255 alive) on a torus. The cells of each component are unwrapped, so a component
+// crossing an edge of the board has coordinates past that edge rather than being split in two.
+func Components(frame []uint8, width, height int) [][]util.Cell {
+	return label(frame, width, height, 1)
+}
+
+// label finds the groups of alive cells that are connected through cells at most radius apart.
+func label(frame []uint8, width, height, radius int) [][]util.Cell {
+	visited := make([]bool, len(frame))
+	var groups [][]util.Cell
+	for start := range frame {
+		if frame[start] == 0 || visited[start] {
+			continue
+		}
+		visited[start] = true
+		group := []util.Cell{{X: start % width, Y: start / width}}
+		for next := 0; next < len(group); next++ {
+			cell := group[next]
+			for dy := -radius; dy <= radius; dy++ {
+				for dx := -radius; dx <= radius; dx++ {
+					x, y := cell.X+dx, cell.Y+dy
+					index := ((y%height+height)%height)*width + (x%width+width)%width
+					if frame[index] != 0 && !visited[index] {
+						visited[index] = true
+						group = append(group, util.Cell{X: x, Y: y})
+					}
+				}
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// Objects labels the objects on a row-major frame (0 dead, 255 alive) on a torus and classifies
+// them against the Catalogue. Objects are the 8-connected components of alive cells, except that
+// nearby components are first tried together, so that patterns with phases made of several
+// components, like the beacon or the LWSS, are recognised as a single object.
+func Objects(frame []uint8, width, height int) []Object {
+	var objects []Object
+	// Components closer than 3 cells can interact, so they are grouped before classifying.
+	for _, group := range label(frame, width, height, 2) {
+		if object, ok := classify(group, width, height); ok {
+			objects = append(objects, object)
+			continue
+		}
+		for _, component := range splitComponents(group) {
+			object, _ := classify(component, width, height)
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+// classify creates the Object made of the unwrapped cells, reporting whether it is in the Catalogue.
+func classify(cells []util.Cell, width, height int) (Object, bool) {
+	object := Object{
+		Name:   fmt.Sprintf("unknown-%d", len(cells)),
+		Kind:   Unknown,
+		Bounds: cellBounds(cells),
+		Cells:  make([]util.Cell, len(cells)),
+	}
+	// Move the object so its top left corner is on the board.
+	offset := image.Pt(
+		(object.Bounds.Min.X%width+width)%width-object.Bounds.Min.X,
+		(object.Bounds.Min.Y%height+height)%height-object.Bounds.Min.Y,
+	)
+	object.Bounds = object.Bounds.Add(offset)
+	for i, cell := range cells {
+		object.Cells[i] = util.Cell{X: (cell.X + offset.X) % width, Y: (cell.Y + offset.Y) % height}
+	}
+
+	pattern, ok := Lookup(cells)
+	if ok {
+		object.Name = pattern.Name
+		object.Kind = pattern.Kind
+		object.Period = pattern.Period
+	}
+	return object, ok
+}
+
+// splitComponents splits unwrapped cells into their 8-connected components.
+func splitComponents(cells []util.Cell) [][]util.Cell {
+	remaining := make(map[util.Cell]bool, len(cells))
+	for _, cell := range cells {
+		remaining[cell] = true
+	}
+	var components [][]util.Cell
+	for _, start := range cells {
+		if !remaining[start] {
+			continue
+		}
+		delete(remaining, start)
+		component := []util.Cell{start}
+		for next := 0; next < len(component); next++ {
+			cell := component[next]
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					neighbour := util.Cell{X: cell.X + dx, Y: cell.Y + dy}
+					if remaining[neighbour] {
+						delete(remaining, neighbour)
+						component = append(component, neighbour)
+					}
+				}
+			}
+		}
+		components = append(components, component)
+	}
+	return components
+}
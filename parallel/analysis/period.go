@@ -0,0 +1,212 @@
+package analysis
+
+import (
+	"fmt"
+	"image"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Evolution is what a pattern does on an unbounded plane, see FindPeriod.
+type Evolution struct {
+	// Kind is Unknown when the pattern died out or no period was found.
+	Kind Kind
+	Died bool
+	// Generation is the first generation of the periodic pattern, 0 when the pattern returns to itself.
+	// When the pattern died out it is the generation it died at.
+	Generation int
+	Period     int
+	// Displacement is how far a spaceship or the front of a puffer moves every period.
+	Displacement image.Point
+	// Population is the number of alive cells at Generation.
+	Population int
+}
+
+// Speed returns the speed of a spaceship or puffer in the usual notation, like c/4 or 2c/5.
+func (e Evolution) Speed() string {
+	cells := abs(e.Displacement.X)
+	if abs(e.Displacement.Y) > cells {
+		cells = abs(e.Displacement.Y)
+	}
+	if cells == 0 || e.Period == 0 {
+		return "0"
+	}
+	divisor := gcd(cells, e.Period)
+	cells, period := cells/divisor, e.Period/divisor
+	switch {
+	case period == 1 && cells == 1:
+		return "c"
+	case period == 1:
+		return fmt.Sprintf("%vc", cells)
+	case cells == 1:
+		return fmt.Sprintf("c/%v", period)
+	default:
+		return fmt.Sprintf("%vc/%v", cells, period)
+	}
+}
+
+func (e Evolution) String() string {
+	switch {
+	case e.Died:
+		return fmt.Sprintf("dies at generation %v", e.Generation)
+	case e.Kind == Unknown:
+		return "no period found"
+	}
+	description := fmt.Sprintf("%v with period %v", e.Kind, e.Period)
+	if e.Displacement != (image.Point{}) {
+		description += fmt.Sprintf(", moving (%v, %v) every period at %v %v",
+			e.Displacement.X, e.Displacement.Y, e.Speed(), heading(e.Displacement))
+	}
+	if e.Generation > 0 {
+		description += fmt.Sprintf(", from generation %v", e.Generation)
+	}
+	return description
+}
+
+// generation is a pattern after some number of steps.
+type generation struct {
+	cells  []util.Cell
+	bounds image.Rectangle
+}
+
+// FindPeriod evolves cells on an unbounded plane for at most generations steps, until the pattern
+// is a translation of an earlier generation. Patterns that never repeat are checked for a front that
+// does, moving away from the debris it leaves behind, which makes them puffers.
+func FindPeriod(cells []util.Cell, generations int) Evolution {
+	// seen holds the generation each shape was first seen at
+	seen := map[string]int{}
+	var history []generation
+	for turn := 0; turn <= generations; turn++ {
+		if turn > 0 {
+			cells = Step(cells)
+		}
+		if len(cells) == 0 {
+			return Evolution{Died: true, Generation: turn}
+		}
+		history = append(history, generation{cells: cells, bounds: cellBounds(cells)})
+		key := shapeKey(append([]util.Cell(nil), cells...))
+		if first, ok := seen[key]; ok {
+			e := Evolution{
+				Generation:   first,
+				Period:       turn - first,
+				Displacement: history[turn].bounds.Min.Sub(history[first].bounds.Min),
+				Population:   len(cells),
+			}
+			switch {
+			case e.Displacement != (image.Point{}):
+				e.Kind = Spaceship
+			case e.Period == 1:
+				e.Kind = StillLife
+			default:
+				e.Kind = Oscillator
+			}
+			return e
+		}
+		seen[key] = turn
+	}
+	return findPuffer(history)
+}
+
+// findPuffer looks for a period over which the front of the last generation moved and stayed the same shape,
+// for the last two periods. The front is as deep as the first generation is long in the direction it moves.
+func findPuffer(history []generation) Evolution {
+	last := len(history) - 1
+	for period := 1; 2*period <= last; period++ {
+		displacement, ok := frontDisplacement(history[last-period].bounds, history[last].bounds)
+		if !ok {
+			continue
+		}
+		earlier, ok := frontDisplacement(history[last-2*period].bounds, history[last-period].bounds)
+		if !ok || earlier != displacement {
+			continue
+		}
+		direction := image.Pt(sign(displacement.X), sign(displacement.Y))
+		depth := extent(history[0].cells, direction)
+		front := frontCells(history[last].cells, direction, depth)
+		key := shapeKey(append([]util.Cell(nil), front...))
+		if key != shapeKey(frontCells(history[last-period].cells, direction, depth)) ||
+			key != shapeKey(frontCells(history[last-2*period].cells, direction, depth)) {
+			continue
+		}
+		// Evolved on its own, the front must reproduce itself and leave debris, otherwise it is only a spaceship
+		// leaving, like the gliders of a glider gun, or part of one.
+		evolved := front
+		for i := 0; i < period; i++ {
+			evolved = Step(evolved)
+		}
+		if len(evolved) <= len(front) || shapeKey(frontCells(evolved, direction, depth)) != key {
+			continue
+		}
+		return Evolution{Kind: Puffer, Period: period, Displacement: displacement}
+	}
+	return Evolution{Population: len(history[last].cells)}
+}
+
+// frontDisplacement returns how far the front of a pattern moved between two bounds, when its back did not follow.
+func frontDisplacement(before, after image.Rectangle) (image.Point, bool) {
+	dx, okX := edgeShift(before.Min.X, before.Max.X, after.Min.X, after.Max.X)
+	dy, okY := edgeShift(before.Min.Y, before.Max.Y, after.Min.Y, after.Max.Y)
+	return image.Pt(dx, dy), okX && okY && (dx != 0 || dy != 0)
+}
+
+// edgeShift returns how far one edge of a range moved when the other edge stayed or moved the other way.
+func edgeShift(min, max, newMin, newMax int) (int, bool) {
+	switch {
+	case newMin == min && newMax == max:
+		return 0, true
+	case newMin < min && newMax >= max:
+		return newMin - min, true
+	case newMax > max && newMin <= min:
+		return newMax - max, true
+	}
+	return 0, false
+}
+
+// extent returns how long cells are in a direction.
+func extent(cells []util.Cell, direction image.Point) int {
+	lowest, highest := 0, 0
+	for i, cell := range cells {
+		projection := cell.X*direction.X + cell.Y*direction.Y
+		if i == 0 || projection < lowest {
+			lowest = projection
+		}
+		if i == 0 || projection > highest {
+			highest = projection
+		}
+	}
+	return highest - lowest + 1
+}
+
+// frontCells returns the cells within depth of the front of cells, in a direction.
+func frontCells(cells []util.Cell, direction image.Point, depth int) []util.Cell {
+	leading := 0
+	for i, cell := range cells {
+		if projection := cell.X*direction.X + cell.Y*direction.Y; i == 0 || projection > leading {
+			leading = projection
+		}
+	}
+	var front []util.Cell
+	for _, cell := range cells {
+		if cell.X*direction.X+cell.Y*direction.Y > leading-depth {
+			front = append(front, cell)
+		}
+	}
+	return front
+}
+
+func sign(x int) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,205 @@
+package analysis
+
+import (
+	"fmt"
+	"image"
+)
+
+// Velocity is an average speed in cells per turn. Positive Y is down the board.
+type Velocity struct {
+	X, Y float64
+}
+
+func (v Velocity) String() string {
+	return fmt.Sprintf("(%.3f, %.3f)", v.X, v.Y)
+}
+
+// Movement is the position of a tracked spaceship when the tracker is updated.
+type Movement struct {
+	ID   int
+	Name string
+	// FirstTurn is the turn the spaceship was first seen at, from Start.
+	FirstTurn int
+	Start     image.Point
+	// Position is the top left corner of the spaceship on the board.
+	Position image.Point
+	// Distance is how far the spaceship has moved since it was first seen, counting every
+	// time it wrapped around the edges of the board.
+	Distance image.Point
+	// Velocity is the average velocity of the spaceship since it was first seen.
+	Velocity Velocity
+	// Heading is the compass direction of Distance, with north at the top of the board.
+	Heading string
+}
+
+// Collision is a tracked spaceship that could no longer be found, because it hit another object.
+type Collision struct {
+	ID   int
+	Name string
+	// Position is where the spaceship was last seen.
+	Position image.Point
+	// Debris names the objects found around Position afterwards.
+	Debris []string
+}
+
+// Tracker follows the spaceships on a torus across turns.
+type Tracker struct {
+	width, height int
+	turn          int
+	nextID        int
+	tracks        []*track
+}
+
+// track is a spaceship followed by a Tracker.
+type track struct {
+	id        int
+	name      string
+	firstTurn int
+	start     image.Point
+	bounds    image.Rectangle
+	distance  image.Point
+}
+
+// NewTracker creates a tracker for a board of the given size.
+func NewTracker(width, height int) *Tracker {
+	return &Tracker{width: width, height: height}
+}
+
+// Update matches the spaceships in objects, found after turn turns, to the ones found by the previous update.
+// It returns the movement of every spaceship, including new ones, and the spaceships that were not found again.
+func (t *Tracker) Update(turn int, objects []Object) ([]Movement, []Collision) {
+	elapsed := turn - t.turn
+	t.turn = turn
+
+	unmatched := t.tracks
+	t.tracks = nil
+	for _, object := range objects {
+		if object.Kind != Spaceship {
+			continue
+		}
+		// Spaceships move at most one cell a turn, and their bounds change a little between phases.
+		best, bestDistance := -1, elapsed+2
+		for i, tr := range unmatched {
+			if tr.name != object.Name {
+				continue
+			}
+			if d := chebyshev(t.wrappedDelta(tr.bounds.Min, object.Bounds.Min)); d <= bestDistance {
+				best, bestDistance = i, d
+			}
+		}
+		if best < 0 {
+			t.nextID++
+			t.tracks = append(t.tracks, &track{
+				id:        t.nextID,
+				name:      object.Name,
+				firstTurn: turn,
+				start:     object.Bounds.Min,
+				bounds:    object.Bounds,
+			})
+			continue
+		}
+		tr := unmatched[best]
+		unmatched = append(unmatched[:best], unmatched[best+1:]...)
+		tr.distance = tr.distance.Add(t.wrappedDelta(tr.bounds.Min, object.Bounds.Min))
+		tr.bounds = object.Bounds
+		t.tracks = append(t.tracks, tr)
+	}
+
+	movements := make([]Movement, 0, len(t.tracks))
+	for _, tr := range t.tracks {
+		movements = append(movements, tr.movement(turn))
+	}
+	var collisions []Collision
+	for _, tr := range unmatched {
+		collisions = append(collisions, Collision{
+			ID:       tr.id,
+			Name:     tr.name,
+			Position: tr.bounds.Min,
+			Debris:   t.debris(tr.bounds, objects),
+		})
+	}
+	return movements, collisions
+}
+
+// movement reports the track after turn turns.
+func (tr *track) movement(turn int) Movement {
+	var velocity Velocity
+	if turns := turn - tr.firstTurn; turns > 0 {
+		velocity = Velocity{X: float64(tr.distance.X) / float64(turns), Y: float64(tr.distance.Y) / float64(turns)}
+	}
+	return Movement{
+		ID:        tr.id,
+		Name:      tr.name,
+		FirstTurn: tr.firstTurn,
+		Start:     tr.start,
+		Position:  tr.bounds.Min,
+		Distance:  tr.distance,
+		Velocity:  velocity,
+		Heading:   heading(tr.distance),
+	}
+}
+
+// debris names the objects close to bounds.
+func (t *Tracker) debris(bounds image.Rectangle, objects []Object) []string {
+	var names []string
+	for _, object := range objects {
+		delta := t.wrappedDelta(bounds.Min, object.Bounds.Min)
+		near := image.Rect(-object.Bounds.Dx()-2, -object.Bounds.Dy()-2, bounds.Dx()+3, bounds.Dy()+3)
+		if delta.In(near) {
+			names = append(names, object.Name)
+		}
+	}
+	return names
+}
+
+// wrappedDelta returns the shortest displacement from a to b on the torus.
+func (t *Tracker) wrappedDelta(a, b image.Point) image.Point {
+	return image.Pt(wrap(b.X-a.X, t.width), wrap(b.Y-a.Y, t.height))
+}
+
+// wrap returns the value equal to d modulo size that is closest to 0.
+func wrap(d, size int) int {
+	d = (d%size + size) % size
+	if d > size/2 {
+		d -= size
+	}
+	return d
+}
+
+// chebyshev returns the number of king moves needed to move by d.
+func chebyshev(d image.Point) int {
+	if d.X < 0 {
+		d.X = -d.X
+	}
+	if d.Y < 0 {
+		d.Y = -d.Y
+	}
+	if d.X > d.Y {
+		return d.X
+	}
+	return d.Y
+}
+
+// heading returns the compass direction of d, or "" when it is zero.
+func heading(d image.Point) string {
+	vertical, horizontal := "", ""
+	// A direction counts when it is at least half of the other one, between the 8 compass points.
+	if d.Y < 0 && -2*d.Y >= abs(d.X) {
+		vertical = "N"
+	} else if d.Y > 0 && 2*d.Y >= abs(d.X) {
+		vertical = "S"
+	}
+	if d.X > 0 && 2*d.X >= abs(d.Y) {
+		horizontal = "E"
+	} else if d.X < 0 && -2*d.X >= abs(d.Y) {
+		horizontal = "W"
+	}
+	return vertical + horizontal
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/analysis"
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestCensus tests that the objects of a board are found and classified, including objects
+// that wrap around the edges and patterns whose phase is split into several components.
+func TestCensus(t *testing.T) {
+	for _, pattern := range analysis.Catalogue {
+		found, ok := analysis.Lookup(pictureCells(pattern.Picture, 0, 0))
+		if !ok || found.Name != pattern.Name {
+			t.Errorf("%v is not found in the catalogue", pattern.Name)
+		}
+	}
+
+	width, height := 32, 32
+	frame := make([]uint8, width*height)
+	draw := func(x, y int, picture ...string) {
+		for _, cell := range pictureCells(picture, x, y) {
+			frame[(cell.Y%height)*width+cell.X%width] = 255
+		}
+	}
+	draw(6, 6, "OO", "OO")
+	draw(10, 2, "OOO")
+	draw(20, 2, "O..", "O..", "O..")
+	// 跨越右下角的滑翔机
+	//a glider wrapping around the bottom right corner
+	draw(31, 31, ".O.", "..O", "OOO")
+	// 第二相位的信标由两个连通分量组成
+	//the second phase of a beacon is made of two components
+	draw(2, 10, "OO..", "O...", "...O", "..OO")
+	// 一个不在目录中的物体
+	//an object which is not in the catalogue
+	draw(20, 20, "OO", "O.")
+	census := analysis.TakeCensus(frame, width, height)
+
+	expected := map[string]int{"block": 1, "blinker": 2, "glider": 1, "beacon": 1, "unknown-3": 1}
+	if len(census.Counts) != len(expected) {
+		t.Fatalf("Expected census %v, got %v", expected, census.Counts)
+	}
+	for name, count := range expected {
+		if census.Counts[name] != count {
+			t.Fatalf("Expected census %v, got %v", expected, census.Counts)
+		}
+	}
+	for _, object := range census.Objects {
+		for _, cell := range object.Cells {
+			if cell.X < 0 || cell.X >= width || cell.Y < 0 || cell.Y >= height {
+				t.Fatalf("%v has cell %v outside the board", object.Name, cell)
+			}
+		}
+		if object.Name == "glider" && (object.Bounds.Min.X != 31 || object.Bounds.Max.X != 34) {
+			t.Fatalf("Expected the glider to wrap from x 31 to 34, got %v", object.Bounds)
+		}
+	}
+}
+
+// TestCensusComplete tests that a census is taken at the end of a run and its report saved.
+func TestCensusComplete(t *testing.T) {
+	p := gol.Params{
+		Turns:       100,
+		Threads:     8,
+		ImageWidth:  64,
+		ImageHeight: 64,
+		OutputDir:   t.TempDir(),
+		Census:      true,
+	}
+	alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	var censuses []gol.CensusComplete
+	for event := range events {
+		if e, ok := event.(gol.CensusComplete); ok {
+			censuses = append(censuses, e)
+		}
+	}
+	if len(censuses) != 1 || censuses[0].CompletedTurns != p.Turns {
+		t.Fatalf("Expected one census at turn %v, got %v", p.Turns, censuses)
+	}
+	cells := 0
+	for _, object := range censuses[0].Census.Objects {
+		cells += len(object.Cells)
+	}
+	if cells != alive[p.Turns] {
+		t.Fatalf("Expected the census to contain %v alive cells, got %v", alive[p.Turns], cells)
+	}
+	if _, err := os.Stat(filepath.Join(p.OutputDir, censuses[0].Filename+".txt")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestCensusKey tests that pressing c takes a census while paused, and that censuses requested
+// as the run ends finish before the events channel is closed.
+func TestCensusKey(t *testing.T) {
+	p := gol.Params{
+		Turns:       100000000,
+		Threads:     4,
+		ImageWidth:  16,
+		ImageHeight: 16,
+		OutputDir:   t.TempDir(),
+	}
+	keyPresses := make(chan rune, 10)
+	events := make(chan gol.Event, 1000)
+	go gol.Run(p, events, keyPresses)
+
+	keyPresses <- 'p'
+	paused := false
+	pausedTurn := 0
+	censuses := 0
+	quitting := false
+	for event := range events {
+		switch e := event.(type) {
+		case gol.StateChange:
+			if e.NewState == gol.Paused {
+				paused = true
+				pausedTurn = e.CompletedTurns
+				keyPresses <- 'c'
+			} else if e.NewState == gol.Executing {
+				paused = false
+			} else if e.NewState == gol.Quitting {
+				quitting = true
+			}
+		case gol.CensusComplete:
+			if quitting {
+				t.Fatal("Census completed after the run quit")
+			}
+			censuses++
+			if censuses == 1 {
+				if !paused || e.CompletedTurns != pausedTurn {
+					t.Fatalf("Expected the census while paused at turn %v, got turn %v", pausedTurn, e.CompletedTurns)
+				}
+				keyPresses <- 'p'
+				// 运行结束时仍在请求统计
+				//censuses are still requested as the run ends
+				keyPresses <- 'c'
+				keyPresses <- 'q'
+				keyPresses <- 'c'
+				keyPresses <- 'c'
+			}
+		}
+	}
+	if censuses == 0 {
+		t.Fatal("No census was taken")
+	}
+}
+
+// pictureCells returns the alive cells of a picture drawn with 'O' at x, y.
+func pictureCells(picture []string, x, y int) []util.Cell {
+	var cells []util.Cell
+	for dy, row := range picture {
+		for dx, c := range row {
+			if c == 'O' {
+				cells = append(cells, util.Cell{X: x + dx, Y: y + dy})
+			}
+		}
+	}
+	return cells
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestCheckpoint tests that a run resumed from a checkpoint continues counting turns from the saved turn
+// and ends with the same board as an uninterrupted run.
+func TestCheckpoint(t *testing.T) {
+	for _, size := range []int{16, 64} {
+		p := gol.Params{
+			Turns:       50,
+			Threads:     4,
+			ImageWidth:  size,
+			ImageHeight: size,
+			OutputDir:   t.TempDir(),
+		}
+		p.Checkpoint = filepath.Join(p.OutputDir, "checkpoint")
+		// 64x64使用压缩的检查点 64x64 uses a compressed checkpoint
+		p.Compress = size == 64
+		t.Run(fmt.Sprintf("%dx%d", size, size), func(t *testing.T) {
+			events := make(chan gol.Event)
+			go gol.Run(p, events, nil)
+			for range events {
+			}
+
+			saved, turn, err := gol.CheckpointParams(p.Checkpoint)
+			util.Check(err)
+			if turn != p.Turns || saved.ImageWidth != size || saved.ImageHeight != size {
+				t.Fatalf("checkpoint saved turn %v of %vx%v, expected turn %v of %vx%v",
+					turn, saved.ImageWidth, saved.ImageHeight, p.Turns, size, size)
+			}
+
+			resumed := p
+			resumed.Turns = 100
+			resumed.Checkpoint = ""
+			resumed.Resume = p.Checkpoint
+			events = make(chan gol.Event)
+			go gol.Run(resumed, events, nil)
+			firstTurn := 0
+			var cells []util.Cell
+			for event := range events {
+				switch e := event.(type) {
+				case gol.TurnComplete:
+					if firstTurn == 0 {
+						firstTurn = e.CompletedTurns
+					}
+				case gol.FinalTurnComplete:
+					if e.CompletedTurns != resumed.Turns {
+						t.Errorf("expected FinalTurnComplete at turn %v, got %v", resumed.Turns, e.CompletedTurns)
+					}
+					cells = e.Alive
+				}
+			}
+			if firstTurn != p.Turns+1 {
+				t.Errorf("expected the resumed run to complete turn %v first, got %v", p.Turns+1, firstTurn)
+			}
+			expectedAlive := readAliveCells(
+				fmt.Sprintf("check/images/%vx%vx100.pgm", size, size),
+				size,
+				size,
+			)
+			assertEqualBoard(t, cells, expectedAlive, resumed)
+		})
+	}
+}
@@ -68,6 +68,58 @@ func TestAlive(t *testing.T) {
 	t.Fatal("not enough AliveCellsCount events received")
 }
 
+// TestAliveEveryTurns checks that AliveCellsCount events reported every n turns
+// match CompletedTurns and the counts in check/alive.
+func TestAliveEveryTurns(t *testing.T) {
+	p := gol.Params{
+		Turns:           100,
+		Threads:         8,
+		ImageWidth:      64,
+		ImageHeight:     64,
+		OutputDir:       t.TempDir(),
+		AliveEveryTurns: 10,
+	}
+	alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	reports := 0
+	for event := range events {
+		switch e := event.(type) {
+		case gol.AliveCellsCount:
+			reports++
+			if e.CompletedTurns != reports*p.AliveEveryTurns {
+				t.Fatalf("Expected report %v at turn %v, got turn %v", reports, reports*p.AliveEveryTurns, e.CompletedTurns)
+			}
+			if e.CellsCount != alive[e.CompletedTurns] {
+				t.Fatalf("At turn %v expected %v alive cells, got %v instead", e.CompletedTurns, alive[e.CompletedTurns], e.CellsCount)
+			}
+		}
+	}
+	if reports != p.Turns/p.AliveEveryTurns {
+		t.Fatalf("Expected %v AliveCellsCount events, got %v", p.Turns/p.AliveEveryTurns, reports)
+	}
+}
+
+// TestCountPixels checks that the pixels set in the SDL window are counted and kept when it is repainted.
+func TestCountPixels(t *testing.T) {
+	if sdlWindow == nil {
+		t.Skip("The SDL window is disabled by -noVis")
+	}
+	sdlWindow.ClearPixels()
+	defer sdlWindow.ClearPixels()
+	sdlWindow.SetPixel(0, 0)
+	sdlWindow.SetPixel(1, 0)
+	sdlWindow.SetPixel(1, 0)
+	sdlWindow.SetPixel(511, 511)
+	if count := sdlWindow.CountPixels(); count != 3 {
+		t.Fatalf("Expected 3 pixels after setting them, got %v", count)
+	}
+	sdlWindow.SetPalette(util.DefaultPalette)
+	if count := sdlWindow.CountPixels(); count != 3 {
+		t.Fatalf("Expected 3 pixels after repainting, got %v", count)
+	}
+}
+
 func readAliveCounts(width, height int) map[int]int {
 	f, err := os.Open("check/alive/" + fmt.Sprintf("%vx%v.csv", width, height))
 	util.Check(err)
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestCycleDetector tests that periodic worlds are detected from the events of a run.
+func TestCycleDetector(t *testing.T) {
+	tests := []struct {
+		name          string
+		picture       []string
+		delivery      gol.FlipDelivery
+		start, period int
+	}{
+		{"blinker", []string{"OOO"}, gol.BatchedFlips, 0, 2},
+		{"blinker-per-cell", []string{"OOO"}, gol.PerCellFlips, 1, 2},
+		{"glider", []string{".O.", "..O", "OOO"}, gol.BatchedFlips, 0, 64},
+		{"dying", []string{"OO"}, gol.BatchedFlips, 1, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := gol.Params{
+				Turns:        1000,
+				Threads:      4,
+				ImageWidth:   16,
+				ImageHeight:  16,
+				InputDir:     t.TempDir(),
+				OutputDir:    t.TempDir(),
+				FlipDelivery: test.delivery,
+			}
+			frame := make([]byte, p.ImageWidth*p.ImageHeight)
+			for _, cell := range pictureCells(test.picture, 6, 6) {
+				frame[cell.Y*p.ImageWidth+cell.X] = 255
+			}
+			err := ioutil.WriteFile(filepath.Join(p.InputDir, "16x16.pgm"), append([]byte("P5\n16 16\n255\n"), frame...), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			events := make(chan gol.Event)
+			keyPresses := make(chan rune, 1)
+			go gol.Run(p, events, keyPresses)
+			detector := gol.NewCycleDetector(p)
+			for event := range events {
+				if detector.Handle(event) {
+					keyPresses <- 'q'
+				}
+			}
+			if !detector.Found() || detector.Start() != test.start || detector.Period() != test.period {
+				t.Fatalf("Expected a cycle of period %v from turn %v, got %v from turn %v",
+					test.period, test.start, detector.Period(), detector.Start())
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/sdl"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// editorBoard is a board of 16x16 cells drawn 4 pixels wide, for testing the editor without a window.
+type editorBoard map[util.Cell]bool
+
+func (b editorBoard) CellAt(x, y int32) (util.Cell, bool) {
+	if x < 0 || y < 0 || x >= 64 || y >= 64 {
+		return util.Cell{}, false
+	}
+	return util.Cell{X: int(x / 4), Y: int(y / 4)}, true
+}
+
+func (b editorBoard) Alive(x, y int) bool {
+	return b[util.Cell{X: x, Y: y}]
+}
+
+// TestEditor tests that dragging the mouse toggles every cell on its path once,
+// and that a cell whose edit was dropped can be painted again.
+func TestEditor(t *testing.T) {
+	board := editorBoard{{X: 2, Y: 2}: true}
+	edits := make(chan gol.Edit, 100)
+	editor := sdl.NewEditor(edits)
+	received := func() []util.Cell {
+		var cells []util.Cell
+		for len(edits) > 0 {
+			cells = append(cells, (<-edits).(gol.Toggle).Cells...)
+		}
+		return cells
+	}
+
+	// 从死细胞开始拖动，经过的死细胞都被画成活的，已经活着的细胞不变
+	//dragging from a dead cell paints the dead cells passed over alive, leaving the alive cell alone
+	editor.Start(board, 1, 1)
+	editor.Move(board, 17, 17)
+	editor.Move(board, 5, 5)
+	editor.Stop()
+	editor.Move(board, 60, 60)
+	expected := []util.Cell{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 3, Y: 3}, {X: 4, Y: 4}}
+	if cells := received(); !sameCells(cells, expected) {
+		t.Errorf("Expected edits toggling %v, got %v", expected, cells)
+	}
+
+	// 通道已满时编辑被丢弃，再次经过时重新发送
+	//edits are dropped while the channel is full, and sent again when the cell is passed over again
+	full := make(chan gol.Edit, 1)
+	editor = sdl.NewEditor(full)
+	editor.Start(board, 40, 40)
+	editor.Move(board, 44, 40)
+	if len(full) != 1 {
+		t.Fatalf("Expected one edit in the full channel, got %v", len(full))
+	}
+	<-full
+	editor.Move(board, 40, 40)
+	editor.Move(board, 48, 40)
+	if len(full) != 1 || (<-full).(gol.Toggle).Cells[0] != (util.Cell{X: 11, Y: 10}) {
+		t.Errorf("Expected the dropped edit of cell 11,10 to be sent again")
+	}
+}
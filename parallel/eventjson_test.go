@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestEventJSON tests that the events of a run survive being written as NDJSON and decoded again.
+func TestEventJSON(t *testing.T) {
+	for _, delivery := range []gol.FlipDelivery{gol.PerCellFlips, gol.BatchedFlips} {
+		p := gol.Params{
+			Turns:        10,
+			Threads:      4,
+			ImageWidth:   16,
+			ImageHeight:  16,
+			OutputDir:    t.TempDir(),
+			FlipDelivery: delivery,
+		}
+		events := make(chan gol.Event)
+		go gol.Run(p, events, nil)
+		var sent []gol.Event
+		var buffer bytes.Buffer
+		encoder := gol.NewEventEncoder(&buffer)
+		for event := range events {
+			sent = append(sent, event)
+			err := encoder.Encode(event)
+			util.Check(err)
+		}
+
+		decoded := make(chan gol.Event)
+		go func() {
+			err := gol.ReadEvents(&buffer, decoded)
+			util.Check(err)
+		}()
+		i := 0
+		for event := range decoded {
+			if i >= len(sent) {
+				t.Fatalf("decoded more events than were sent: %#v", event)
+			}
+			if !reflect.DeepEqual(event, sent[i]) {
+				t.Fatalf("event %v decoded as %#v, expected %#v", i, event, sent[i])
+			}
+			i++
+		}
+		if i != len(sent) {
+			t.Fatalf("decoded %v of %v events", i, len(sent))
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Package export records the images of a run from the gol.Event stream, without needing SDL.
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/gif"
+	"os"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// GIFOptions chooses which turns are recorded and how they are drawn.
+type GIFOptions struct {
+	From   int // first turn to record
+	To     int // last turn to record, 0 records until the end of the run
+	Stride int // record every Stride-th turn from From
+	Scale  int // size in pixels of each cell
+	Delay  int // delay between frames in 100ths of a second
+	// MaxFrames is the most frames kept in memory for the gif, later turns are left out. Defaults to 1000.
+	MaxFrames int
+	// Palette colours the cells, by age when it has age colours.
+	Palette util.Palette
+}
+
+// DefaultMaxFrames is the number of frames a gif is limited to when GIFOptions.MaxFrames is not set.
+const DefaultMaxFrames = 1000
+
+// FrameLimitError is returned by Save when turns were left out of the gif because it reached MaxFrames frames.
+type FrameLimitError struct {
+	MaxFrames int
+	Turn      int // first turn left out
+}
+
+func (e FrameLimitError) Error() string {
+	return fmt.Sprintf("gif stopped at %d frames, turns from %d on were left out: use a larger -gif-stride or an earlier -gif-to",
+		e.MaxFrames, e.Turn)
+}
+
+// GIFRecorder rebuilds the board from CellFlipped or CellsFlipped events and captures a frame
+// on every TurnComplete event that falls within the recorded turns.
+// The initial world is captured too when From is the first turn of the run, which needs CellsFlipped events
+// to tell the initial world apart from the cells flipped by the first turn.
+type GIFRecorder struct {
+	params  gol.Params
+	options GIFOptions
+	frame   []uint8
+	// ages follows the age of every cell when the palette colours cells by age, otherwise it is nil.
+	ages *util.CellAges
+	anim gif.GIF
+	// started is set once the initial world has been received.
+	started bool
+	// limit is set once a frame was left out because of MaxFrames.
+	limit *FrameLimitError
+}
+
+// NewGIFRecorder creates a recorder for a run with the given Params.
+func NewGIFRecorder(p gol.Params, o GIFOptions) *GIFRecorder {
+	if o.Stride < 1 {
+		o.Stride = 1
+	}
+	if o.Scale < 1 {
+		o.Scale = 1
+	}
+	if o.MaxFrames < 1 {
+		o.MaxFrames = DefaultMaxFrames
+	}
+	if o.Palette == (util.Palette{}) {
+		o.Palette = util.DefaultPalette
+	}
+	r := &GIFRecorder{
+		params:  p,
+		options: o,
+		frame:   make([]uint8, p.ImageWidth*p.ImageHeight),
+	}
+	if o.Palette.Aged() {
+		r.ages = util.NewCellAges(p.ImageWidth, p.ImageHeight)
+	}
+	return r
+}
+
+// Handle updates the board with a single event, capturing a frame if needed.
+func (r *GIFRecorder) Handle(event gol.Event) {
+	switch e := event.(type) {
+	case gol.CellFlipped:
+		r.flip(e.Cell)
+	case gol.CellsFlipped:
+		for _, cell := range e.Cells {
+			r.flip(cell)
+		}
+		// the first CellsFlipped event is the initial world
+		if !r.started {
+			r.started = true
+			if r.wants(e.CompletedTurns) {
+				r.capture(e.CompletedTurns)
+			}
+		}
+	case gol.TurnComplete:
+		r.started = true
+		if r.ages != nil {
+			r.ages.CompleteTurn()
+		}
+		if r.wants(e.CompletedTurns) {
+			r.capture(e.CompletedTurns)
+		}
+	}
+}
+
+func (r *GIFRecorder) flip(cell util.Cell) {
+	r.frame[cell.Y*r.params.ImageWidth+cell.X] ^= 0xFF
+	if r.ages != nil {
+		r.ages.Flip(cell.X, cell.Y)
+	}
+}
+
+// wants reports whether the given turn should be recorded.
+func (r *GIFRecorder) wants(turn int) bool {
+	if turn < r.options.From || (r.options.To > 0 && turn > r.options.To) {
+		return false
+	}
+	return (turn-r.options.From)%r.options.Stride == 0
+}
+
+// capture appends the current board to the animation, unless it already has MaxFrames frames.
+func (r *GIFRecorder) capture(turn int) {
+	if len(r.anim.Image) >= r.options.MaxFrames {
+		if r.limit == nil {
+			r.limit = &FrameLimitError{MaxFrames: r.options.MaxFrames, Turn: turn}
+		}
+		return
+	}
+	var img *image.Paletted
+	if r.ages != nil {
+		img = util.AgesImage(r.ages, r.options.Scale, r.options.Palette)
+	} else {
+		img = util.FrameImage(r.frame, r.params.ImageWidth, r.params.ImageHeight, r.options.Scale, r.options.Palette)
+	}
+	r.anim.Image = append(r.anim.Image, img)
+	r.anim.Delay = append(r.anim.Delay, r.options.Delay)
+}
+
+// Frames returns the number of frames captured so far.
+func (r *GIFRecorder) Frames() int {
+	return len(r.anim.Image)
+}
+
+// Save writes the captured frames to path as an animated gif.
+// An empty animation is saved as a single frame of the current board.
+// If turns were left out because of MaxFrames, the gif is still written and a FrameLimitError is returned.
+func (r *GIFRecorder) Save(path string) error {
+	if len(r.anim.Image) == 0 {
+		r.capture(0)
+	}
+	r.anim.Config = image.Config{
+		ColorModel: r.options.Palette.Colours(),
+		Width:      r.params.ImageWidth * r.options.Scale,
+		Height:     r.params.ImageHeight * r.options.Scale,
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	err = gif.EncodeAll(writer, &r.anim)
+	if err != nil {
+		return err
+	}
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+	if r.limit != nil {
+		return *r.limit
+	}
+	return nil
+}
+
+// Record records every event from events until it is closed, then saves the animation to path.
+// events is typically a gol.Hub subscription.
+func Record(r *GIFRecorder, path string, events <-chan gol.Event) error {
+	for event := range events {
+		r.Handle(event)
+	}
+	return r.Save(path)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestFlipDelivery tests that the board rebuilt from flip events matches the final board
+// for every FlipDelivery, and that only the chosen kind of event is sent.
+func TestFlipDelivery(t *testing.T) {
+	p := gol.Params{Turns: 100, Threads: 8, ImageWidth: 64, ImageHeight: 64}
+	expectedAlive := readAliveCells("check/images/64x64x100.pgm", 64, 64)
+	for _, delivery := range []gol.FlipDelivery{gol.PerCellFlips, gol.BatchedFlips, gol.NoFlips} {
+		p.FlipDelivery = delivery
+		t.Run(fmt.Sprintf("delivery-%d", delivery), func(t *testing.T) {
+			board := make([][]byte, p.ImageHeight)
+			for i := range board {
+				board[i] = make([]byte, p.ImageWidth)
+			}
+			cellFlipped, cellsFlipped := 0, 0
+			events := make(chan gol.Event)
+			go gol.Run(p, events, nil)
+			var final []util.Cell
+			for event := range events {
+				switch e := event.(type) {
+				case gol.CellFlipped:
+					cellFlipped++
+					board[e.Cell.Y][e.Cell.X] = ^board[e.Cell.Y][e.Cell.X]
+				case gol.CellsFlipped:
+					cellsFlipped++
+					for _, cell := range e.Cells {
+						board[cell.Y][cell.X] = ^board[cell.Y][cell.X]
+					}
+				case gol.FinalTurnComplete:
+					final = e.Alive
+				}
+			}
+			assertEqualBoard(t, final, expectedAlive, p)
+
+			switch delivery {
+			case gol.PerCellFlips:
+				if cellsFlipped != 0 {
+					t.Errorf("received %v CellsFlipped events with per cell delivery", cellsFlipped)
+				}
+			case gol.BatchedFlips:
+				if cellFlipped != 0 || cellsFlipped != p.Turns+1 {
+					t.Errorf("expected %v CellsFlipped and no CellFlipped events, got %v and %v",
+						p.Turns+1, cellsFlipped, cellFlipped)
+				}
+			case gol.NoFlips:
+				if cellFlipped != 0 || cellsFlipped != 0 {
+					t.Errorf("received %v CellFlipped and %v CellsFlipped events with no delivery", cellFlipped, cellsFlipped)
+				}
+				return
+			}
+			var cells []util.Cell
+			for y := range board {
+				for x := range board[y] {
+					if board[y][x] == 255 {
+						cells = append(cells, util.Cell{X: x, Y: y})
+					}
+				}
+			}
+			assertEqualBoard(t, cells, expectedAlive, p)
+		})
+	}
+}
+
+// BenchmarkFlipDelivery compares the cost of per cell and batched flip events for a consumer
+// that only counts the flipped cells.
+func BenchmarkFlipDelivery(b *testing.B) {
+	os.Stdout = nil // Disable all program output apart from benchmark results
+	for _, delivery := range []gol.FlipDelivery{gol.PerCellFlips, gol.BatchedFlips} {
+		p := gol.Params{
+			Turns:        benchLength,
+			Threads:      8,
+			ImageWidth:   512,
+			ImageHeight:  512,
+			OutputDir:    b.TempDir(),
+			FlipDelivery: delivery,
+		}
+		b.Run(fmt.Sprintf("delivery-%d", delivery), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				events := make(chan gol.Event, 1000)
+				go gol.Run(p, events, nil)
+				flipped := 0
+				for event := range events {
+					switch e := event.(type) {
+					case gol.CellFlipped:
+						flipped++
+					case gol.CellsFlipped:
+						flipped += len(e.Cells)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/export"
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestGif tests that the gif starts with the initial world and stops at its frame limit.
+func TestGif(t *testing.T) {
+	p := gol.Params{ImageWidth: 5, ImageHeight: 5}
+	recorder := export.NewGIFRecorder(p, export.GIFOptions{From: 0, MaxFrames: 3})
+	recorder.Handle(gol.CellsFlipped{Cells: pictureCells([]string{"OOO"}, 1, 2)})
+	for turn := 1; turn <= 5; turn++ {
+		recorder.Handle(gol.CellsFlipped{CompletedTurns: turn - 1, Cells: []util.Cell{{X: 1, Y: 2}, {X: 3, Y: 2}, {X: 2, Y: 1}, {X: 2, Y: 3}}})
+		recorder.Handle(gol.TurnComplete{CompletedTurns: turn})
+	}
+	path := filepath.Join(t.TempDir(), "blinker.gif")
+	err := recorder.Save(path)
+	if limit, ok := err.(export.FrameLimitError); !ok || limit.Turn != 3 {
+		t.Errorf("Expected the gif to stop before turn 3, got %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	anim, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 3 {
+		t.Fatalf("Expected 3 frames, got %v", len(anim.Image))
+	}
+	// 第一帧是水平的初始闪烁器
+	//the first frame is the initial horizontal blinker
+	first := anim.Image[0]
+	if first.At(1, 2) != util.DefaultPalette.Alive || first.At(2, 1) != util.DefaultPalette.Dead {
+		t.Errorf("Expected the initial world in the first frame")
+	}
+}
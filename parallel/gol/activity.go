@@ -0,0 +1,130 @@
+package gol
+
+import (
+	"bufio"
+	"fmt"
+	"image/png"
+	"math"
+	"path/filepath"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// activityTracker 记录每个细胞最后一次变化的回合和翻转的总次数
+// keeps the turn every cell last changed on and how many times it has flipped
+type activityTracker struct {
+	startTurn  int
+	lastChange []int
+	flips      []int
+	width      int
+}
+
+// newActivityTracker 从第turn回合开始记录，Params.Activity 未设置时返回 nil
+// starts keeping activity from turn turn, returns nil when Params.Activity is not set
+func newActivityTracker(p Params, turn int) *activityTracker {
+	if !p.Activity {
+		return nil
+	}
+	t := &activityTracker{
+		startTurn:  turn,
+		lastChange: make([]int, p.ImageWidth*p.ImageHeight),
+		flips:      make([]int, p.ImageWidth*p.ImageHeight),
+		width:      p.ImageWidth,
+	}
+	for i := range t.lastChange {
+		t.lastChange[i] = turn
+	}
+	return t
+}
+
+// apply 记录完成turn回合时翻转的细胞
+// records the cells flipped to complete turn turns
+func (t *activityTracker) apply(turn int, flippedCells []util.Cell) {
+	if t == nil {
+		return
+	}
+	for _, cell := range flippedCells {
+		index := cell.Y*t.width + cell.X
+		t.lastChange[index] = turn
+		t.flips[index]++
+	}
+}
+
+// flipMap 按对数比例返回每个细胞的翻转次数，翻转最多的细胞为1
+// returns the number of flips of every cell on a log scale, 1 for the cells that flipped the most
+func (t *activityTracker) flipMap() []float64 {
+	most := 0
+	for _, flips := range t.flips {
+		if flips > most {
+			most = flips
+		}
+	}
+	values := make([]float64, len(t.flips))
+	if most == 0 {
+		return values
+	}
+	for i, flips := range t.flips {
+		values[i] = math.Log1p(float64(flips)) / math.Log1p(float64(most))
+	}
+	return values
+}
+
+// ageMap 按对数比例返回每个细胞距离上次变化的回合数，刚变化的细胞为1，从未变化的细胞为0
+// returns how recently every cell changed on a log scale, 1 for the cells that just changed
+// and 0 for the cells that never changed
+func (t *activityTracker) ageMap(turn int) []float64 {
+	values := make([]float64, len(t.lastChange))
+	oldest := turn - t.startTurn
+	if oldest == 0 {
+		return values
+	}
+	for i, lastChange := range t.lastChange {
+		values[i] = 1 - math.Log1p(float64(turn-lastChange))/math.Log1p(float64(oldest))
+	}
+	return values
+}
+
+// output 将翻转次数和细胞年龄保存为图像，未记录时不做任何事
+// saves the number of flips and the age of the cells as images, does nothing when activity is not kept
+func (t *activityTracker) output(c distributorChannels, p Params, turn int) {
+	if t == nil {
+		return
+	}
+	name := expandTemplate(p.OutputTemplate, p, turn)
+	outputHeatmap(c, name+"-flips", turn, t.flipMap())
+	outputHeatmap(c, name+"-age", turn, t.ageMap(turn))
+}
+
+// outputHeatmap 将0到1之间的数值保存为名为outFilename的png图像
+// saves values from 0 to 1 as a png image named outFilename
+func outputHeatmap(c distributorChannels, outFilename string, turn int, values []float64) {
+	c.ioCommand <- ioHeatmap
+	c.ioFilename <- outFilename
+	c.ioHeatmap <- values
+
+	c.ioCommand <- ioCheckIdle
+	<-c.ioIdle
+	c.events <- ImageOutputComplete{CompletedTurns: turn, Filename: outFilename}
+}
+
+// writeHeatmap receives a map of values from 0 to 1 and writes it as a png image
+// coloured by Params.ActivityGradient.
+func (io *ioState) writeHeatmap() {
+	// Request a filename from the distributor.
+	filename := <-io.channels.filename
+	values := <-io.channels.heatmap
+	if len(values) != io.params.ImageWidth*io.params.ImageHeight {
+		panic("Incorrect frame size")
+	}
+	gradient, ok := util.Gradients[io.params.ActivityGradient]
+	if !ok {
+		panic("Unknown gradient " + io.params.ActivityGradient)
+	}
+
+	img := util.HeatmapImage(values, io.params.ImageWidth, io.params.ImageHeight, io.params.ImageScale, gradient)
+	writeAtomic(filepath.Join(io.params.OutputDir, filename+".png"), false, func(writer *bufio.Writer) error {
+		return png.Encode(writer, img)
+	})
+
+	fmt.Println("File", filename, "output done!")
+}
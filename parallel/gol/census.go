@@ -0,0 +1,37 @@
+package gol
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+
+	"uk.ac.bris.cs/gameoflife/analysis"
+)
+
+// outputCensus 统计世界中的物体，将报告写入输出目录并发送 CensusComplete 事件
+// takes a census of the objects in a copy of the world (see worldToFrame), writes the report
+// to the output directory and sends a CensusComplete event
+func outputCensus(c distributorChannels, p Params, turn int, frame []uint8) {
+	census := analysis.TakeCensus(frame, p.ImageWidth, p.ImageHeight)
+	filename := expandTemplate(p.OutputTemplate, p, turn) + "-census"
+	c.ioCommand <- ioCensus
+	c.ioFilename <- filename
+	c.ioCensus <- census
+
+	c.ioCommand <- ioCheckIdle
+	<-c.ioIdle
+	c.events <- CensusComplete{CompletedTurns: turn, Filename: filename, Census: census}
+}
+
+// writeCensus receives a census and writes its report as a text file.
+func (io *ioState) writeCensus() {
+	// Request a filename from the distributor.
+	filename := <-io.channels.filename
+	census := <-io.channels.census
+
+	writeAtomic(filepath.Join(io.params.OutputDir, filename+".txt"), false, func(writer *bufio.Writer) error {
+		return census.WriteReport(writer)
+	})
+
+	fmt.Println("Census", filename, "output done!")
+}
@@ -0,0 +1,132 @@
+package gol
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+const (
+	checkpointVersion = 1
+	// torusTopology 是引擎使用的拓扑：上下、左右边界相连
+	// torusTopology is the topology used by the engine: the top and bottom, left and right edges are connected
+	torusTopology = "torus"
+)
+
+// checkpoint 保存恢复运行所需的全部信息，使用 encoding/gob 编码
+// checkpoint holds everything needed to resume a run, encoded with encoding/gob
+type checkpoint struct {
+	Version  int
+	Turn     int
+	Params   Params
+	Rule     string
+	Topology string
+	// Seed 是生成初始世界所用的随机种子（见 Params.Soup），从图像载入时为nil，因为0也是有效的种子
+	// Seed is the random seed the initial world was generated from (see Params.Soup),
+	// nil when it was loaded from an image as 0 is a valid seed too
+	Seed  *int64
+	World []uint8
+}
+
+// newCheckpoint 根据当前回合和世界的副本创建检查点
+// creates a checkpoint from the current turn and a copy of the world (see worldToFrame)
+func newCheckpoint(p Params, turn int, frame []uint8) checkpoint {
+	var seed *int64
+	if p.Soup.Density > 0 {
+		seed = &p.Soup.Seed
+	}
+	return checkpoint{
+		Version:  checkpointVersion,
+		Turn:     turn,
+		Params:   p,
+		Rule:     conwayRule,
+		Topology: torusTopology,
+		Seed:     seed,
+		World:    frame,
+	}
+}
+
+// outputCheckpoint 将检查点写入 Params.Checkpoint，未设置时不做任何事
+// writes a checkpoint to Params.Checkpoint, does nothing when it is not set
+func outputCheckpoint(c distributorChannels, p Params, turn int, frame []uint8) {
+	if p.Checkpoint == "" {
+		return
+	}
+	c.ioCommand <- ioCheckpoint
+	c.ioFilename <- p.Checkpoint
+	c.ioCheckpointOut <- newCheckpoint(p, turn, frame)
+}
+
+// inputCheckpoint 从 Params.Resume 读取检查点，并检查它与当前参数是否匹配
+// reads the checkpoint at Params.Resume and checks that it matches the current params
+func inputCheckpoint(c distributorChannels, p Params) checkpoint {
+	c.ioCommand <- ioResume
+	c.ioFilename <- p.Resume
+	saved := <-c.ioCheckpointIn
+	if saved.Params.ImageWidth != p.ImageWidth {
+		panic("Incorrect width")
+	}
+	if saved.Params.ImageHeight != p.ImageHeight {
+		panic("Incorrect height")
+	}
+	if saved.Rule != conwayRule || saved.Topology != torusTopology {
+		panic("Unsupported rule or topology " + saved.Rule + " " + saved.Topology)
+	}
+	return saved
+}
+
+// writeCheckpoint receives a checkpoint and writes it to the requested path.
+func (io *ioState) writeCheckpoint() {
+	// Request a filename from the distributor.
+	filename := <-io.channels.filename
+	saved := <-io.channels.checkpointOut
+
+	writeAtomic(filename, io.params.Compress, func(writer *bufio.Writer) error {
+		return gob.NewEncoder(writer).Encode(saved)
+	})
+
+	fmt.Println("Checkpoint", filename, "output done!")
+}
+
+// readCheckpoint opens a checkpoint and sends it to the distributor.
+func (io *ioState) readCheckpoint() {
+	// Request a filename from the distributor.
+	filename := <-io.channels.filename
+
+	saved, ioError := readCheckpointFile(filename)
+	util.Check(ioError)
+	io.channels.checkpointIn <- saved
+
+	fmt.Println("Checkpoint", filename, "input done!")
+}
+
+// readCheckpointFile decodes the checkpoint at path.
+func readCheckpointFile(path string) (checkpoint, error) {
+	var saved checkpoint
+	file, err := openDecompressed(path)
+	if err != nil {
+		return saved, err
+	}
+	defer file.Close()
+	err = gob.NewDecoder(bufio.NewReader(file)).Decode(&saved)
+	if err != nil {
+		return saved, err
+	}
+	if saved.Version != checkpointVersion {
+		return saved, fmt.Errorf("unsupported checkpoint version %d", saved.Version)
+	}
+	if len(saved.World) != saved.Params.ImageWidth*saved.Params.ImageHeight {
+		return saved, fmt.Errorf("checkpoint world has %d cells, expected %dx%d",
+			len(saved.World), saved.Params.ImageWidth, saved.Params.ImageHeight)
+	}
+	return saved, nil
+}
+
+// CheckpointParams returns the Params a checkpoint was saved with and the number of turns it had completed,
+// so a run can be resumed with the same settings.
+func CheckpointParams(path string) (Params, int, error) {
+	saved, err := readCheckpointFile(path)
+	return saved.Params, saved.Turn, err
+}
@@ -0,0 +1,110 @@
+package gol
+
+import (
+	"math/rand"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// CycleDetector rebuilds the world from the CellFlipped or CellsFlipped events of a run and detects
+// when it becomes periodic, by keeping a hash of the world after every turn.
+// With BatchedFlips the initial world is hashed too, otherwise hashing starts after the first turn.
+type CycleDetector struct {
+	width int
+	frame []uint8
+	keys  []uint64
+	hash  uint64
+	// seen 记录每个哈希值第一次出现的回合
+	// seen holds the turn each hash was first seen after
+	seen    map[uint64]int
+	started bool
+
+	start, period int
+}
+
+// NewCycleDetector creates a detector for a run with the given Params.
+func NewCycleDetector(p Params) *CycleDetector {
+	// 每个细胞一个固定的随机数，世界的哈希值是所有存活细胞的随机数的异或
+	//a fixed random number for every cell, the hash of the world is the xor of the numbers of the alive cells
+	random := rand.New(rand.NewSource(1))
+	keys := make([]uint64, p.ImageWidth*p.ImageHeight)
+	for i := range keys {
+		keys[i] = random.Uint64()
+	}
+	return &CycleDetector{
+		width: p.ImageWidth,
+		frame: make([]uint8, p.ImageWidth*p.ImageHeight),
+		keys:  keys,
+		seen:  make(map[uint64]int),
+	}
+}
+
+// Handle updates the world with a single event. It reports true on the TurnComplete event
+// that completes the first cycle, after which Start and Period are set.
+func (d *CycleDetector) Handle(event Event) bool {
+	switch e := event.(type) {
+	case CellFlipped:
+		d.flip(e.Cell)
+	case CellsFlipped:
+		for _, cell := range e.Cells {
+			d.flip(cell)
+		}
+		if !d.started {
+			d.started = true
+			d.seen[d.hash] = e.CompletedTurns
+		}
+	case TurnComplete:
+		d.started = true
+		if d.period > 0 {
+			return false
+		}
+		if turn, ok := d.seen[d.hash]; ok {
+			d.start = turn
+			d.period = e.CompletedTurns - turn
+			return true
+		}
+		d.seen[d.hash] = e.CompletedTurns
+	}
+	return false
+}
+
+func (d *CycleDetector) flip(cell util.Cell) {
+	index := cell.Y*d.width + cell.X
+	d.frame[index] ^= 0xFF
+	d.hash ^= d.keys[index]
+}
+
+// Hash returns the hash of the current world.
+func (d *CycleDetector) Hash() uint64 {
+	return d.hash
+}
+
+// HashCells returns the hash of a world with only the given cells alive. The hash of a world
+// is the xor of the hashes of its parts, so it can be used to take cells out of a world's hash.
+func (d *CycleDetector) HashCells(cells []util.Cell) uint64 {
+	var hash uint64
+	for _, cell := range cells {
+		hash ^= d.keys[cell.Y*d.width+cell.X]
+	}
+	return hash
+}
+
+// Found reports whether the world has become periodic.
+func (d *CycleDetector) Found() bool {
+	return d.period > 0
+}
+
+// Start returns the first turn of the cycle, when the world became periodic.
+func (d *CycleDetector) Start() int {
+	return d.start
+}
+
+// Period returns the period of the cycle, 1 for a world that stopped changing.
+func (d *CycleDetector) Period() int {
+	return d.period
+}
+
+// Frame returns the current world, row by row (0 dead, 255 alive). It must not be modified.
+func (d *CycleDetector) Frame() []uint8 {
+	return d.frame
+}
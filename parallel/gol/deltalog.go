@@ -0,0 +1,279 @@
+package gol
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// A delta log records a run compactly: the initial world followed by the cells flipped in every turn
+// and by every edit, the same data carried by CellFlipped events. Cells are stored as the difference
+// between consecutive cell indices (y*width + x), encoded as signed varints. The layout is:
+//
+//	magic "GOLDELTA", then uvarint version, width, height and starting turn
+//	a turn record with the alive cells of the initial world
+//	a turn record for every turn, and an edit record for every edit, in the order they happened
+//
+// Every record is a uvarint number of cells times 2, plus 1 for an edit record, followed by the
+// index differences of the cells. Version 1 logs have no edit records and store the number of cells alone.
+const (
+	deltaLogMagic   = "GOLDELTA"
+	deltaLogVersion = 2
+)
+
+// deltaRecord is the kind of a record in a delta log.
+type deltaRecord int
+
+const (
+	turnRecord deltaRecord = iota
+	editRecord
+)
+
+// deltaLogWriter 在运行时把每回合翻转的细胞写入增量日志
+// writes the cells flipped in every turn to a delta log while running
+type deltaLogWriter struct {
+	file   *os.File
+	output *compressedWriter
+	writer *bufio.Writer
+	width  int
+	buffer [binary.MaxVarintLen64]byte
+}
+
+// newDeltaLogWriter 创建增量日志并写入初始世界，Params.DeltaLog 未设置时返回 nil
+// creates the delta log and writes the initial world, returns nil when Params.DeltaLog is not set
+func newDeltaLogWriter(p Params, turn int, frame []uint8) *deltaLogWriter {
+	if p.DeltaLog == "" {
+		return nil
+	}
+	file, err := os.Create(p.DeltaLog)
+	util.Check(err)
+	output := newCompressedWriter(file, p.Compress)
+	l := &deltaLogWriter{file: file, output: output, writer: bufio.NewWriter(output), width: p.ImageWidth}
+	_, _ = l.writer.WriteString(deltaLogMagic)
+	l.writeUvarint(deltaLogVersion)
+	l.writeUvarint(p.ImageWidth)
+	l.writeUvarint(p.ImageHeight)
+	l.writeUvarint(turn)
+
+	var alive []util.Cell
+	for i, value := range frame {
+		if value == 255 {
+			alive = append(alive, util.Cell{X: i % p.ImageWidth, Y: i / p.ImageWidth})
+		}
+	}
+	l.writeTurn(alive)
+	return l
+}
+
+func (l *deltaLogWriter) writeUvarint(value int) {
+	n := binary.PutUvarint(l.buffer[:], uint64(value))
+	_, _ = l.writer.Write(l.buffer[:n])
+}
+
+func (l *deltaLogWriter) writeVarint(value int) {
+	n := binary.PutVarint(l.buffer[:], int64(value))
+	_, _ = l.writer.Write(l.buffer[:n])
+}
+
+// writeTurn 写入一个回合翻转的细胞
+// writes the cells flipped in one turn
+func (l *deltaLogWriter) writeTurn(flippedCells []util.Cell) {
+	if l == nil {
+		return
+	}
+	l.writeRecord(turnRecord, flippedCells)
+}
+
+// writeEdit 写入回合之间一次编辑翻转的细胞
+// writes the cells flipped by an edit between turns
+func (l *deltaLogWriter) writeEdit(flippedCells []util.Cell) {
+	if l == nil {
+		return
+	}
+	l.writeRecord(editRecord, flippedCells)
+}
+
+// writeRecord 写入一条记录，并立即刷新，使崩溃前的回合和编辑都能被回放
+// writes a record and flushes, so every turn and edit before a crash can be replayed
+func (l *deltaLogWriter) writeRecord(kind deltaRecord, cells []util.Cell) {
+	l.writeUvarint(len(cells)*2 + int(kind))
+	previous := 0
+	for _, cell := range cells {
+		index := cell.Y*l.width + cell.X
+		l.writeVarint(index - previous)
+		previous = index
+	}
+	err := l.writer.Flush()
+	util.Check(err)
+	err = l.output.Flush()
+	util.Check(err)
+}
+
+// close 关闭增量日志
+// closes the delta log
+func (l *deltaLogWriter) close() {
+	if l == nil {
+		return
+	}
+	err := l.writer.Flush()
+	util.Check(err)
+	err = l.output.Close()
+	util.Check(err)
+	err = l.file.Close()
+	util.Check(err)
+}
+
+// DeltaLog reads a delta log written by a run with Params.DeltaLog set.
+type DeltaLog struct {
+	Width, Height int
+	// StartTurn is the number of turns completed before the first turn in the log.
+	StartTurn int
+	// FlipDelivery chooses how Replay reports flipped cells, like Params.FlipDelivery.
+	FlipDelivery FlipDelivery
+
+	version int
+	file    io.ReadCloser
+	reader  *bufio.Reader
+}
+
+// OpenDeltaLog opens the delta log at path and reads its header. Gzipped logs are decompressed transparently.
+func OpenDeltaLog(path string) (*DeltaLog, error) {
+	file, err := openDecompressed(path)
+	if err != nil {
+		return nil, err
+	}
+	l := &DeltaLog{file: file, reader: bufio.NewReader(file)}
+	magic := make([]byte, len(deltaLogMagic))
+	_, err = io.ReadFull(l.reader, magic)
+	if err != nil || string(magic) != deltaLogMagic {
+		file.Close()
+		return nil, errors.New("not a delta log")
+	}
+	var header [4]int
+	for i := range header {
+		header[i], err = l.readUvarint()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	if header[0] < 1 || header[0] > deltaLogVersion {
+		file.Close()
+		return nil, fmt.Errorf("unsupported delta log version %d", header[0])
+	}
+	l.version, l.Width, l.Height, l.StartTurn = header[0], header[1], header[2], header[3]
+	return l, nil
+}
+
+// Params returns Params describing the logged world.
+func (l *DeltaLog) Params() Params {
+	return Params{ImageWidth: l.Width, ImageHeight: l.Height}
+}
+
+// Close closes the underlying file.
+func (l *DeltaLog) Close() error {
+	return l.file.Close()
+}
+
+func (l *DeltaLog) readUvarint() (int, error) {
+	value, err := binary.ReadUvarint(l.reader)
+	return int(value), err
+}
+
+// readRecord reads the kind and the cells of the next record. It returns io.EOF at the end of the log,
+// including when the last record was cut short by a crash.
+func (l *DeltaLog) readRecord() (deltaRecord, []util.Cell, error) {
+	count, err := l.readUvarint()
+	if err != nil {
+		return turnRecord, nil, io.EOF
+	}
+	kind := turnRecord
+	if l.version >= 2 {
+		kind = deltaRecord(count % 2)
+		count /= 2
+	}
+	if count > l.Width*l.Height {
+		return kind, nil, errors.New("corrupt delta log")
+	}
+	cells := make([]util.Cell, count)
+	index := 0
+	for i := range cells {
+		delta, err := binary.ReadVarint(l.reader)
+		if err != nil {
+			return kind, nil, io.EOF
+		}
+		index += int(delta)
+		if index < 0 || index >= l.Width*l.Height {
+			return kind, nil, errors.New("corrupt delta log")
+		}
+		cells[i] = util.Cell{X: index % l.Width, Y: index / l.Width}
+	}
+	return kind, cells, nil
+}
+
+// Replay sends the logged run to events as if it was running: CellFlipped (or CellsFlipped) events for the
+// initial world, then CellFlipped and TurnComplete events for every turn, CellFlipped and EditComplete
+// events for every edit, and finally FinalTurnComplete.
+// It waits delay between turns, handles 'p' and 'q' from keyPresses (which may be nil)
+// and closes events when done.
+func (l *DeltaLog) Replay(events chan<- Event, keyPresses <-chan rune, delay time.Duration) error {
+	defer close(events)
+	world := build(l.Height, l.Width)
+	turn := l.StartTurn
+
+	flip := func(cells []util.Cell) {
+		for _, cell := range cells {
+			world[cell.Y][cell.X] = ^world[cell.Y][cell.X]
+		}
+		sendFlippedCells(events, l.FlipDelivery, turn, cells)
+	}
+
+	_, initial, err := l.readRecord()
+	if err != nil {
+		return err
+	}
+	flip(initial)
+
+	quit := false
+	for !quit {
+		kind, cells, err := l.readRecord()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		flip(cells)
+		if kind == editRecord {
+			events <- EditComplete{CompletedTurns: turn}
+			continue
+		}
+		turn++
+		events <- TurnComplete{CompletedTurns: turn}
+
+		select {
+		case key := <-keyPresses:
+			if key == 'q' {
+				quit = true
+			} else if key == 'p' {
+				events <- StateChange{CompletedTurns: turn, NewState: Paused}
+				for key = <-keyPresses; key != 'p' && key != 'q'; key = <-keyPresses {
+				}
+				quit = key == 'q'
+				events <- StateChange{CompletedTurns: turn, NewState: Executing}
+			}
+		default:
+		}
+		time.Sleep(delay)
+	}
+
+	p := l.Params()
+	events <- FinalTurnComplete{CompletedTurns: turn, Alive: findAliveCells(p, makeImmutableWorld(world))}
+	events <- StateChange{CompletedTurns: turn, NewState: Quitting}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"sync"
 	"time"
+	"uk.ac.bris.cs/gameoflife/analysis"
 	"uk.ac.bris.cs/gameoflife/util"
 )
 
@@ -12,8 +13,19 @@ type distributorChannels struct {
 	ioCommand  chan<- ioCommand
 	ioIdle     <-chan bool
 	ioFilename chan<- string
-	ioOutput   chan<- uint8
-	ioInput    <-chan uint8
+	ioOutput   chan<- []uint8
+	ioInput    <-chan []uint8
+
+	ioCheckpointOut chan<- checkpoint
+	ioCheckpointIn  <-chan checkpoint
+
+	ioCensus  chan<- analysis.Census
+	ioHeatmap chan<- []float64
+
+	// done 在运行结束时关闭，使io、键盘和ticker线程都退出，不再引用世界
+	// done is closed when the run has finished, so the io, keyboard and ticker goroutines exit
+	// and no longer keep the world reachable
+	done chan bool
 }
 
 // build 接收长度和宽度并生成一个指定长度x宽度的2D矩阵
@@ -118,27 +130,53 @@ func isAlive(x, y, width, height int, immutableWorld func(y, x int) uint8) bool
 	return false
 }
 
-// outputPGM 将世界转换为pgm图像
-// turn the world into pgm image
-func outputPGM(c distributorChannels, p Params, turn int, world [][]uint8) {
-	c.ioCommand <- ioOutput
-	outFilename := strconv.Itoa(p.ImageHeight) + "x" + strconv.Itoa(p.ImageWidth) + "x" + strconv.Itoa(turn)
-	c.ioFilename <- outFilename
-	// 输出世界，ioOutput通道会每次传递一个值，从世界的左上角到右下角
-	//print the world, io channel will pass one value at a time
-	//from the top left to the bottom right corner of the world
-
+// worldToFrame 将世界逐行复制到一个连续的数组中，从世界的左上角到右下角
+// copies the world row by row into one contiguous array, from the top left to the bottom right corner
+func worldToFrame(p Params, world [][]uint8) []uint8 {
+	frame := make([]uint8, p.ImageHeight*p.ImageWidth)
 	for y := 0; y < p.ImageHeight; y++ {
-		for x := 0; x < p.ImageWidth; x++ {
-			c.ioOutput <- world[y][x]
-		}
+		copy(frame[y*p.ImageWidth:(y+1)*p.ImageWidth], world[y])
+	}
+	return frame
+}
+
+// frameWorld 返回与frame共享内存的世界，修改世界即修改frame
+// returns a world sharing its memory with frame, so changing the world changes the frame
+func frameWorld(p Params, frame []uint8) [][]uint8 {
+	world := make([][]uint8, p.ImageHeight)
+	for y := range world {
+		world[y] = frame[y*p.ImageWidth : (y+1)*p.ImageWidth]
 	}
+	return world
+}
+
+// outputPGM 将世界的副本转换为名为outFilename的pgm图像
+// turn a copy of the world (see worldToFrame) into pgm image named outFilename
+func outputPGM(c distributorChannels, outFilename string, turn int, frame []uint8) {
+	c.ioCommand <- ioOutput
+	c.ioFilename <- outFilename
+	// 输出世界，ioOutput通道一次传递整个世界
+	//print the world, io channel passes the whole world at once
+	c.ioOutput <- frame
 
 	c.ioCommand <- ioCheckIdle
 	<-c.ioIdle
 	c.events <- ImageOutputComplete{CompletedTurns: turn, Filename: outFilename}
 }
 
+// sendFlippedCells 按照 FlipDelivery 发送翻转细胞的事件：每个细胞一个事件、每回合一个事件或不发送
+// sends the events for flipped cells according to FlipDelivery: one per cell, one per turn or none
+func sendFlippedCells(events chan<- Event, delivery FlipDelivery, turn int, cells []util.Cell) {
+	switch delivery {
+	case PerCellFlips:
+		for _, cell := range cells {
+			events <- CellFlipped{CompletedTurns: turn, Cell: cell}
+		}
+	case BatchedFlips:
+		events <- CellsFlipped{CompletedTurns: turn, Cells: cells}
+	}
+}
+
 // 将任务分配到每个线程
 // Allocate tasks to each thread
 func worker(startY, endY int, p Params, immutableWorld func(y, x int) uint8, out chan<- []util.Cell) {
@@ -147,77 +185,210 @@ func worker(startY, endY int, p Params, immutableWorld func(y, x int) uint8, out
 
 // distributor divides the work between workers and interacts with other goroutines.
 // 分工，并与其他 goroutines 交互
-func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
+func distributor(p Params, c distributorChannels, keyPresses <-chan rune, edits <-chan Edit) {
 	world := build(p.ImageHeight, p.ImageWidth)
 
 	turn := 0
-	c.ioCommand <- ioInput
-	c.ioFilename <- strconv.Itoa(p.ImageHeight) + "x" + strconv.Itoa(p.ImageWidth)
+	var frame []uint8
+	if p.Resume != "" {
+		// 从检查点恢复世界和回合数
+		//resume the world and the turn count from a checkpoint
+		saved := inputCheckpoint(c, p)
+		turn = saved.Turn
+		frame = saved.World
+	} else if p.Soup.Density > 0 {
+		// 生成随机的世界，在发送初始细胞之后保存下来以便复现
+		//generate a random world, saved once the initial cells are sent so the run can be reproduced
+		frame = generateSoup(p)
+	} else if p.Pattern != nil {
+		// 在空的世界中央放置图案
+		//place the pattern in the centre of an empty world
+		frame = make([]uint8, p.ImageWidth*p.ImageHeight)
+		pattern := Stamp{Pattern: *p.Pattern, X: (p.ImageWidth - p.Pattern.Width) / 2, Y: (p.ImageHeight - p.Pattern.Height) / 2}
+		pattern.apply(p, frameWorld(p, frame))
+	} else {
+		c.ioCommand <- ioInput
+		c.ioFilename <- strconv.Itoa(p.ImageHeight) + "x" + strconv.Itoa(p.ImageWidth)
+		frame = <-c.ioInput
+	}
 
-	var processLock sync.Mutex
-	// 初始化世界，ioInput管道会每次传递一个值，从世界的左上角到右下角
-	//initialize the world, io channel pass a value at each time, from top left to bottom right corner.
+	// processLock 保护世界和回合数，distributor修改它们时持有写锁，其他线程读取时持有读锁
+	//processLock guards the world and the turn count, the distributor holds the write lock while changing them
+	//and other goroutines hold the read lock while reading them
+	var processLock sync.RWMutex
+	// 初始化世界，ioInput管道一次传递整个世界，从世界的左上角到右下角
+	//initialize the world, io channel passes the whole world at once, from top left to bottom right corner.
+	var initialCells []util.Cell
 	for y := 0; y < p.ImageHeight; y++ {
+		copy(world[y], frame[y*p.ImageWidth:(y+1)*p.ImageWidth])
 		for x := 0; x < p.ImageWidth; x++ {
-			value := <-c.ioInput
-			world[y][x] = value
-			if value == 255 {
-				c.events <- CellFlipped{Cell: util.Cell{X: x, Y: y}}
+			if world[y][x] == 255 {
+				initialCells = append(initialCells, util.Cell{X: x, Y: y})
 			}
 		}
 	}
+	sendFlippedCells(c.events, p.FlipDelivery, turn, initialCells)
+	if p.Resume == "" && p.Soup.Density > 0 {
+		outputPGM(c, expandTemplate(p.OutputTemplate, p, turn)+"-soup", turn, frame)
+	}
 
 	immutableWorld := makeImmutableWorld(world)
+	deltaLog := newDeltaLogWriter(p, turn, frame)
+	population := newPopulationTracker(p, initialCells)
+	objects := newObjectTracker(p, turn, frame)
+	activity := newActivityTracker(p, turn)
 
-	// ticker子线程，每两秒报告一次AliveCellsCount
-	//ticker subthread that reports AliveCellsCount every two seconds
-	ticker := time.NewTicker(2 * time.Second)
-	go func() {
+	// applyEdit 在回合之间修改世界并发送翻转细胞的事件，只在distributor中调用
+	//applyEdit changes the world between turns and sends the events of the flipped cells, only called by the distributor
+	applyEdit := func(edit Edit) {
+		processLock.Lock()
+		flippedCells := edit.apply(p, world)
+		sendFlippedCells(c.events, p.FlipDelivery, turn, flippedCells)
+		deltaLog.writeEdit(flippedCells)
+		population.apply(world, flippedCells)
+		activity.apply(turn, flippedCells)
+		c.events <- EditComplete{CompletedTurns: turn}
+		processLock.Unlock()
+	}
+	// applyPendingEdits 在运行时应用所有等待中的编辑，设置 Params.PausedEdits 时丢弃它们
+	//applyPendingEdits applies every waiting edit while running, or discards them with Params.PausedEdits
+	applyPendingEdits := func() {
 		for {
-			<-ticker.C
-			processLock.Lock()
-			c.events <- AliveCellsCount{CompletedTurns: turn, CellsCount: countAliveCells(p, immutableWorld)}
-			processLock.Unlock()
+			select {
+			case edit := <-edits:
+				if !p.PausedEdits {
+					applyEdit(edit)
+				}
+			default:
+				return
+			}
 		}
-	}()
+	}
+
+	// ticker子线程，每隔 Params.AliveInterval 报告一次AliveCellsCount，按回合报告时不使用
+	//ticker subthread that reports AliveCellsCount every Params.AliveInterval, unused when reporting by turns
+	ticker := time.NewTicker(p.AliveInterval)
+	if p.AliveEveryTurns > 0 {
+		ticker.Stop()
+	} else {
+		go func() {
+			for {
+				select {
+				case <-ticker.C:
+				case <-c.done:
+					return
+				}
+				processLock.RLock()
+				select {
+				case <-c.done:
+					// 运行已经结束，事件通道已经关闭
+					//the run has finished and the events channel is closed
+					processLock.RUnlock()
+					return
+				default:
+				}
+				c.events <- AliveCellsCount{CompletedTurns: turn, CellsCount: countAliveCells(p, immutableWorld)}
+				processLock.RUnlock()
+			}
+		}()
+	}
+
+	// 定期快照，按回合数或按时间间隔保存世界
+	//periodic snapshots, saving the world every N turns or every interval
+	snapshots := newSnapshotter(p)
 
 	// quit线程在键盘按q时提示distributor停止处理回合并退出
 	//quit thread prompts distributor to stop processing back and exit when keyboard presses q
 	quit := make(chan bool)
 	isForceQuit := false
-	// keyboard controller子线程，当键盘输入指定按键时做出响应
+	// 键盘按p时通过pauses通知distributor在回合之间暂停或继续
+	//pauses tells the distributor to pause or resume between turns when the keyboard presses p
+	pauses := make(chan bool, 10)
+	// 键盘按c时通过censuses通知distributor在回合之间统计物体，使统计在运行结束前完成
+	//censuses tells the distributor to take a census between turns when the keyboard presses c,
+	//so every census finishes before the run ends
+	censuses := make(chan bool, 10)
+	// keyboard controller子线程，当键盘输入指定按键时做出响应，读取世界时不需要等待回合计算完成
 	//keyboard controller subthread, which responds to keystrokes when they are entered.
+	//reading the world does not wait for the turn being calculated
 	go func() {
 		var key rune
 		for {
-			key = <-keyPresses
+			select {
+			case key = <-keyPresses:
+			case <-c.done:
+				return
+			}
 			if key == 'q' {
-				quit <- true
+				select {
+				case quit <- true:
+				case <-c.done:
+					return
+				}
 			} else if key == 'p' {
-				processLock.Lock()
-				c.events <- StateChange{CompletedTurns: turn, NewState: Paused}
-				ticker.Stop()
-				paused := true
-				for paused {
-					key = <-keyPresses
-					if key == 'p' {
-						ticker.Reset(2 * time.Second) // 重新开始ticker计时   restart the ticker
-						paused = false
-						c.events <- StateChange{CompletedTurns: turn, NewState: Executing}
-						processLock.Unlock()
-					}
+				select {
+				case pauses <- true:
+				case <-c.done:
+					return
 				}
 			} else if key == 's' {
-				processLock.Lock()
-				go outputPGM(c, p, turn, world)
-				processLock.Unlock()
+				processLock.RLock()
+				go outputPGM(c, expandTemplate(p.OutputTemplate, p, turn), turn, worldToFrame(p, world))
+				processLock.RUnlock()
+			} else if key == 'c' {
+				select {
+				case censuses <- true:
+				case <-c.done:
+					return
+				}
 			}
 		}
 	}()
 
+	// pauseRun 在回合之间暂停，暂停时也可以修改世界，直到再次按p或按q
+	//pauseRun pauses between turns, the world can be edited while paused too, until p or q is pressed again
+	pauseRun := func() {
+		c.events <- StateChange{CompletedTurns: turn, NewState: Paused}
+		ticker.Stop()
+		for {
+			select {
+			case <-pauses:
+				if p.AliveEveryTurns <= 0 {
+					ticker.Reset(p.AliveInterval) // 重新开始ticker计时   restart the ticker
+				}
+				c.events <- StateChange{CompletedTurns: turn, NewState: Executing}
+				return
+			case <-quit:
+				isForceQuit = true
+				return
+			case edit := <-edits:
+				applyEdit(edit)
+			case <-censuses:
+				outputCensus(c, p, turn, worldToFrame(p, world))
+			}
+		}
+	}
+
 	// 根据需要处理的回合数量进行循环
 	//Loop according to the number of rounds to be processed
-	for turn = 0; turn < p.Turns && !isForceQuit; {
+	for turn < p.Turns && !isForceQuit {
+		// 暂停和编辑都发生在回合之间
+		//pausing and edits both happen between turns
+		select {
+		case <-pauses:
+			pauseRun()
+			continue
+		case <-censuses:
+			// 统计当前世界中的物体
+			//take a census of the objects in the current world
+			outputCensus(c, p, turn, worldToFrame(p, world))
+		default:
+		}
+		applyPendingEdits()
+
+		// 只有distributor修改世界，所以计算回合时不需要持有processLock，其他线程仍然可以读取世界
+		//only the distributor changes the world, so processLock is not held while the turn is calculated
+		//and other goroutines can still read the world
 		var outChannels []chan []util.Cell
 		averageHeight := p.ImageHeight / p.Threads
 		restHeight := p.ImageHeight % p.Threads
@@ -240,6 +411,8 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 		for i := 0; i < p.Threads; i++ {
 			flippedCells = append(flippedCells, <-outChannels[i]...)
 		}
+		// 只在修改世界和发送回合的事件时持有processLock
+		//processLock is only held while changing the world and sending the events of the turn
 		processLock.Lock()
 		for _, flippedCell := range flippedCells {
 			if world[flippedCell.Y][flippedCell.X] == 255 {
@@ -247,11 +420,32 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 			} else {
 				world[flippedCell.Y][flippedCell.X] = 255
 			}
-			c.events <- CellFlipped{turn, flippedCell}
 		}
+		sendFlippedCells(c.events, p.FlipDelivery, turn, flippedCells)
+		deltaLog.writeTurn(flippedCells)
+		population.apply(world, flippedCells)
+		activity.apply(turn+1, flippedCells)
 		turn++
 		c.events <- TurnComplete{CompletedTurns: turn}
+		// 按回合报告时，在完成指定回合后立即报告，保证与CompletedTurns一致
+		//when reporting by turns, report right after the turn completes so the count matches CompletedTurns
+		if p.AliveEveryTurns > 0 && turn%p.AliveEveryTurns == 0 {
+			c.events <- AliveCellsCount{CompletedTurns: turn, CellsCount: countAliveCells(p, immutableWorld)}
+		}
+		if population.due(turn) {
+			c.events <- population.report(turn)
+		}
 		processLock.Unlock()
+		// 飞船的识别和跟踪在锁外进行，暂停和按键不需要等待它
+		//finding and following the spaceships happens outside processLock so pausing and key presses do not wait for it
+		if objects.due(turn) {
+			c.events <- objects.report(p, turn, worldToFrame(p, world))
+		}
+		if snapshots.due(turn) {
+			frame = worldToFrame(p, world)
+			snapshots.output(c, p, turn, frame)
+			outputCheckpoint(c, p, turn, frame)
+		}
 		select {
 		case <-quit:
 			isForceQuit = true
@@ -259,9 +453,20 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 			break
 		}
 	}
+	// 最后一个回合之后的编辑也要应用，并写入增量日志
+	//edits made after the last turn are applied too, and written to the delta log
+	applyPendingEdits()
 
 	ticker.Stop()
-	outputPGM(c, p, turn, world)
+	snapshots.stop()
+	frame = worldToFrame(p, world)
+	outputPGM(c, expandTemplate(p.OutputTemplate, p, turn), turn, frame)
+	outputCheckpoint(c, p, turn, frame)
+	if p.Census {
+		outputCensus(c, p, turn, frame)
+	}
+	activity.output(c, p, turn)
+	deltaLog.close()
 	if !isForceQuit {
 		c.events <- FinalTurnComplete{turn, findAliveCells(p, immutableWorld)}
 	}
@@ -272,6 +477,11 @@ func distributor(p Params, c distributorChannels, keyPresses <-chan rune) {
 
 	c.events <- StateChange{turn, Quitting}
 
+	// 结束io、键盘和ticker线程，持有processLock使ticker不会在事件通道关闭后发送事件
+	//stop the io, keyboard and ticker goroutines, holding processLock so the ticker cannot send after events is closed
+	processLock.Lock()
+	close(c.done)
 	// Close the channel to stop the SDL goroutine gracefully. Removing may cause deadlock.
 	close(c.events)
+	processLock.Unlock()
 }
@@ -0,0 +1,104 @@
+package gol
+
+import (
+	"image"
+
+	"uk.ac.bris.cs/gameoflife/patterns"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Edit changes the world of a run between turns, see RunControlled.
+// Coordinates wrap around the edges of the world.
+type Edit interface {
+	// apply 修改世界并返回翻转的细胞，每个细胞最多出现一次
+	// changes the world and returns the flipped cells, each cell at most once
+	apply(p Params, world [][]uint8) []util.Cell
+}
+
+// Stamp copies a pattern into the world with its top left corner at X, Y, after applying Transform.
+// The whole rectangle of the pattern is copied, so the dead cells of the pattern are cleared.
+type Stamp struct {
+	Pattern   patterns.Pattern
+	X, Y      int
+	Transform patterns.Transform
+}
+
+// Clear kills every cell in Rect.
+type Clear struct {
+	Rect image.Rectangle
+}
+
+// Toggle flips the state of every cell in Cells.
+type Toggle struct {
+	Cells []util.Cell
+}
+
+func (s Stamp) apply(p Params, world [][]uint8) []util.Cell {
+	pattern := s.Pattern.Transform(s.Transform)
+	alive := make(map[util.Cell]bool, len(pattern.Cells))
+	for _, cell := range pattern.Cells {
+		alive[cell] = true
+	}
+	var flipped []util.Cell
+	for y := 0; y < pattern.Height; y++ {
+		for x := 0; x < pattern.Width; x++ {
+			var value uint8
+			if alive[util.Cell{X: x, Y: y}] {
+				value = 255
+			}
+			flipped = setCell(p, world, s.X+x, s.Y+y, value, flipped)
+		}
+	}
+	return netFlips(flipped)
+}
+
+func (c Clear) apply(p Params, world [][]uint8) []util.Cell {
+	var flipped []util.Cell
+	for y := c.Rect.Min.Y; y < c.Rect.Max.Y; y++ {
+		for x := c.Rect.Min.X; x < c.Rect.Max.X; x++ {
+			flipped = setCell(p, world, x, y, 0, flipped)
+		}
+	}
+	return netFlips(flipped)
+}
+
+func (t Toggle) apply(p Params, world [][]uint8) []util.Cell {
+	var flipped []util.Cell
+	for _, cell := range t.Cells {
+		x := (cell.X%p.ImageWidth + p.ImageWidth) % p.ImageWidth
+		y := (cell.Y%p.ImageHeight + p.ImageHeight) % p.ImageHeight
+		flipped = setCell(p, world, x, y, world[y][x]^0xFF, flipped)
+	}
+	return netFlips(flipped)
+}
+
+// setCell 将细胞设为value，细胞状态改变时将其加入flipped
+// sets a cell to value, adding it to flipped when its state changes
+func setCell(p Params, world [][]uint8, x, y int, value uint8, flipped []util.Cell) []util.Cell {
+	x = (x%p.ImageWidth + p.ImageWidth) % p.ImageWidth
+	y = (y%p.ImageHeight + p.ImageHeight) % p.ImageHeight
+	if world[y][x] == value {
+		return flipped
+	}
+	world[y][x] = value
+	return append(flipped, util.Cell{X: x, Y: y})
+}
+
+// netFlips 去掉翻转了偶数次的细胞，例如Toggle中重复的细胞或者大于世界而重叠的矩形，
+// 其余细胞只保留第一次出现，使翻转的细胞与修改前后的世界一致
+// removes the cells flipped an even number of times, such as a repeated cell in a Toggle or a rectangle
+// larger than the world overlapping itself, and keeps the first of the others, so the flipped cells match the world
+func netFlips(flippedCells []util.Cell) []util.Cell {
+	flipped := make(map[util.Cell]bool, len(flippedCells))
+	for _, cell := range flippedCells {
+		flipped[cell] = !flipped[cell]
+	}
+	cells := make([]util.Cell, 0, len(flipped))
+	for _, cell := range flippedCells {
+		if flipped[cell] {
+			cells = append(cells, cell)
+			flipped[cell] = false
+		}
+	}
+	return cells
+}
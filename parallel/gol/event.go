@@ -2,6 +2,8 @@ package gol
 
 import (
 	"fmt"
+	"image"
+	"uk.ac.bris.cs/gameoflife/analysis"
 	"uk.ac.bris.cs/gameoflife/util"
 )
 
@@ -15,12 +17,28 @@ type Event interface {
 }
 
 // AliveCellsCount is an Event notifying the user about the number of currently alive cells.
-// This Event should be sent every 2s.
+// This Event should be sent every 2s, or as configured by Params.AliveInterval and Params.AliveEveryTurns.
 type AliveCellsCount struct { // implements Event
 	CompletedTurns int
 	CellsCount     int
 }
 
+// PopulationStats is an Event notifying the user about the population in more detail than AliveCellsCount.
+// This Event is sent every Params.StatsEvery turns, after TurnComplete.
+type PopulationStats struct { // implements Event
+	CompletedTurns int
+	Alive          int
+	// Births and Deaths count the cells born and died since the previous report.
+	Births, Deaths int
+	// Change is the difference in alive cells since the previous report.
+	Change int
+	// Bounds is the smallest rectangle containing every alive cell, empty when there are none.
+	// It does not wrap around the edges of the world.
+	Bounds image.Rectangle
+	// BandDensity is the fraction of alive cells in each of Params.StatsBands horizontal bands, from the top.
+	BandDensity []float64
+}
+
 // ImageOutputComplete is an Event notifying the user about the completion of output.
 // This Event should be sent every time an image has been saved.
 type ImageOutputComplete struct { // implements Event
@@ -28,6 +46,25 @@ type ImageOutputComplete struct { // implements Event
 	Filename       string
 }
 
+// CensusComplete is an Event notifying the user about a census of the objects in the world.
+// This Event is sent when c is pressed, and at the end of the run when Params.Census is set,
+// after the report has been saved.
+type CensusComplete struct { // implements Event
+	CompletedTurns int
+	Filename       string
+	Census         analysis.Census
+}
+
+// ObjectsMoved is an Event notifying the user about the spaceships in the world and how they moved.
+// This Event is sent every Params.TrackEvery turns, after TurnComplete.
+type ObjectsMoved struct { // implements Event
+	CompletedTurns int
+	// Moves has the movement of every spaceship in the world, including the ones seen for the first time.
+	Moves []analysis.Movement
+	// Collisions has the spaceships seen by the previous report that were not found again.
+	Collisions []analysis.Collision
+}
+
 // State represents a change in the state of execution.
 type State int
 
@@ -52,6 +89,32 @@ type CellFlipped struct { // implements Event
 	Cell           util.Cell
 }
 
+// CellsFlipped is an Event notifying the GUI about all the cells that changed state in a turn.
+// It is sent instead of CellFlipped events when Params.FlipDelivery is BatchedFlips,
+// or by a Hub to the subscribers that chose BatchedFlips.
+// Like CellFlipped, it must be sent *before* TurnComplete, and once for all cells that are alive
+// when the image is loaded in. The Cells slice must not be modified.
+type CellsFlipped struct { // implements Event
+	CompletedTurns int
+	Cells          []util.Cell
+}
+
+// FlipDelivery chooses how cells that change state are reported.
+type FlipDelivery int
+
+const (
+	PerCellFlips FlipDelivery = iota // a CellFlipped event for every cell
+	BatchedFlips                     // a single CellsFlipped event for every turn
+	NoFlips                          // no events, for consumers that only want counts
+)
+
+// EditComplete is an Event notifying the GUI that the world was changed by an Edit between turns.
+// SDL will render a frame when this event is sent.
+// The CellFlipped events of the edit are sent *before* EditComplete.
+type EditComplete struct { // implements Event
+	CompletedTurns int
+}
+
 // TurnComplete is an Event notifying the GUI about turn completion.
 // SDL will render a frame when this event is sent.
 // All CellFlipped events must be sent *before* TurnComplete.
@@ -98,6 +161,14 @@ func (event AliveCellsCount) GetCompletedTurns() int {
 	return event.CompletedTurns
 }
 
+func (event PopulationStats) String() string {
+	return fmt.Sprintf("Alive Cells %v (%+d, %v births, %v deaths)", event.Alive, event.Change, event.Births, event.Deaths)
+}
+
+func (event PopulationStats) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
 func (event ImageOutputComplete) String() string {
 	return fmt.Sprintf("File %v output complete", event.Filename)
 }
@@ -106,6 +177,25 @@ func (event ImageOutputComplete) GetCompletedTurns() int {
 	return event.CompletedTurns
 }
 
+func (event CensusComplete) String() string {
+	return fmt.Sprintf("Census %v output complete, %v objects", event.Filename, len(event.Census.Objects))
+}
+
+func (event CensusComplete) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event ObjectsMoved) String() string {
+	if len(event.Collisions) == 0 {
+		return fmt.Sprintf("Spaceships %v moving", len(event.Moves))
+	}
+	return fmt.Sprintf("Spaceships %v moving, %v collided", len(event.Moves), len(event.Collisions))
+}
+
+func (event ObjectsMoved) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
 func (event CellFlipped) String() string {
 	return fmt.Sprintf("")
 }
@@ -114,6 +204,22 @@ func (event CellFlipped) GetCompletedTurns() int {
 	return event.CompletedTurns
 }
 
+func (event CellsFlipped) String() string {
+	return fmt.Sprintf("")
+}
+
+func (event CellsFlipped) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event EditComplete) String() string {
+	return fmt.Sprintf("")
+}
+
+func (event EditComplete) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
 func (event TurnComplete) String() string {
 	return fmt.Sprintf("")
 }
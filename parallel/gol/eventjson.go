@@ -0,0 +1,120 @@
+package gol
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// eventTypes lists every Event type that can be encoded and decoded as JSON, by type name.
+var eventTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, event := range []Event{
+		AliveCellsCount{},
+		PopulationStats{},
+		ImageOutputComplete{},
+		CensusComplete{},
+		ObjectsMoved{},
+		StateChange{},
+		CellFlipped{},
+		CellsFlipped{},
+		EditComplete{},
+		TurnComplete{},
+		FinalTurnComplete{},
+	} {
+		eventType := reflect.TypeOf(event)
+		eventTypes[eventType.Name()] = eventType
+	}
+}
+
+// eventRecord is the JSON form of a single event, written as one line of NDJSON.
+type eventRecord struct {
+	Type           string          `json:"type"`
+	CompletedTurns int             `json:"completedTurns"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+// EventEncoder writes events as newline delimited JSON, one object per event with its
+// type name, CompletedTurns and the event itself as the payload.
+type EventEncoder struct {
+	encoder *json.Encoder
+}
+
+// NewEventEncoder creates an encoder writing to w.
+func NewEventEncoder(w io.Writer) *EventEncoder {
+	return &EventEncoder{encoder: json.NewEncoder(w)}
+}
+
+// Encode writes a single event.
+func (e *EventEncoder) Encode(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return e.encoder.Encode(eventRecord{
+		Type:           reflect.TypeOf(event).Name(),
+		CompletedTurns: event.GetCompletedTurns(),
+		Payload:        payload,
+	})
+}
+
+// EventDecoder reads events written by an EventEncoder back into typed Event values.
+type EventDecoder struct {
+	decoder *json.Decoder
+}
+
+// NewEventDecoder creates a decoder reading from r.
+func NewEventDecoder(r io.Reader) *EventDecoder {
+	return &EventDecoder{decoder: json.NewDecoder(r)}
+}
+
+// Decode reads the next event. It returns io.EOF when there are no more events.
+func (d *EventDecoder) Decode() (Event, error) {
+	var record eventRecord
+	err := d.decoder.Decode(&record)
+	if err != nil {
+		return nil, err
+	}
+	eventType, ok := eventTypes[record.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", record.Type)
+	}
+	event := reflect.New(eventType)
+	err = json.Unmarshal(record.Payload, event.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return event.Elem().Interface().(Event), nil
+}
+
+// WriteEvents encodes every event from events to w until events is closed.
+// events is typically a Hub subscription.
+func WriteEvents(events <-chan Event, w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	encoder := NewEventEncoder(writer)
+	for event := range events {
+		err := encoder.Encode(event)
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// ReadEvents decodes every event from r and sends it to events, then closes events.
+func ReadEvents(r io.Reader, events chan<- Event) error {
+	defer close(events)
+	decoder := NewEventDecoder(r)
+	for {
+		event, err := decoder.Decode()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		events <- event
+	}
+}
@@ -0,0 +1,104 @@
+package gol
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// writeAtomic creates the file at path through write, gzipping it when compress is set.
+// The file is written to a temporary file first and renamed into place once complete,
+// so readers never see a partially written file.
+func writeAtomic(path string, compress bool, write func(writer *bufio.Writer) error) {
+	ioError := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	util.Check(ioError)
+
+	file, ioError := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	util.Check(ioError)
+	defer os.Remove(file.Name())
+	defer file.Close()
+	ioError = file.Chmod(0644)
+	util.Check(ioError)
+
+	output := newCompressedWriter(file, compress)
+	writer := bufio.NewWriter(output)
+	ioError = write(writer)
+	util.Check(ioError)
+	ioError = writer.Flush()
+	util.Check(ioError)
+	ioError = output.Close()
+	util.Check(ioError)
+
+	ioError = file.Sync()
+	util.Check(ioError)
+	ioError = file.Close()
+	util.Check(ioError)
+	ioError = os.Rename(file.Name(), path)
+	util.Check(ioError)
+}
+
+// compressedWriter gzips everything written to it when compression is enabled,
+// and passes it through unchanged otherwise. Closing it does not close the underlying file.
+type compressedWriter struct {
+	io.Writer
+	gzip *gzip.Writer
+}
+
+func newCompressedWriter(file io.Writer, compress bool) *compressedWriter {
+	if !compress {
+		return &compressedWriter{Writer: file}
+	}
+	gz := gzip.NewWriter(file)
+	return &compressedWriter{Writer: gz, gzip: gz}
+}
+
+// Flush flushes compressed data written so far, so it can be read back even if the file is never closed.
+func (w *compressedWriter) Flush() error {
+	if w.gzip == nil {
+		return nil
+	}
+	return w.gzip.Flush()
+}
+
+// Close writes the gzip footer.
+func (w *compressedWriter) Close() error {
+	if w.gzip == nil {
+		return nil
+	}
+	return w.gzip.Close()
+}
+
+// decompressedReader reads a file, decompressing it first if it is gzipped.
+type decompressedReader struct {
+	io.Reader
+	file *os.File
+}
+
+// openDecompressed opens the file at path. Gzipped files are recognised by their magic number,
+// so they are decompressed whatever their name is.
+func openDecompressed(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	buffered := bufio.NewReader(file)
+	magic, _ := buffered.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &decompressedReader{Reader: gz, file: file}, nil
+	}
+	return &decompressedReader{Reader: buffered, file: file}, nil
+}
+
+func (r *decompressedReader) Close() error {
+	return r.file.Close()
+}
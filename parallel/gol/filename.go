@@ -0,0 +1,98 @@
+package gol
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+const (
+	defaultInputDir       = "images"
+	defaultOutputDir      = "out"
+	defaultOutputTemplate = "{size}x{turn}"
+	defaultImageFormat    = "pgm"
+	defaultSnapshot       = "snapshot-{size}x{turn}"
+	defaultStatsBands     = 8
+	defaultAliveInterval  = 2 * time.Second
+	defaultGradient       = "grey"
+
+	// conwayRule 是引擎实现的规则，用于文件名中的 {rule}
+	// conwayRule is the rule implemented by the engine, used for {rule} in filenames.
+	conwayRule = "B3S23"
+)
+
+// runCounter 保证同一进程内生成的 RunID 互不相同
+// runCounter keeps the generated RunIDs unique within one process
+var runCounter uint64
+
+// withDefaults 为未设置的参数填入默认值
+// fills in the default values for the parameters that were not set
+func withDefaults(p Params) Params {
+	if p.InputDir == "" {
+		p.InputDir = defaultInputDir
+	}
+	if p.OutputDir == "" {
+		p.OutputDir = defaultOutputDir
+	}
+	if p.OutputTemplate == "" {
+		p.OutputTemplate = defaultOutputTemplate
+	}
+	if p.RunID == "" {
+		p.RunID = newRunID()
+	}
+	if p.ImageFormat == "" {
+		p.ImageFormat = defaultImageFormat
+	}
+	if p.ImageScale < 1 {
+		p.ImageScale = 1
+	}
+	if p.Palette == (util.Palette{}) {
+		p.Palette = util.DefaultPalette
+	}
+	if p.SnapshotTemplate == "" {
+		p.SnapshotTemplate = defaultSnapshot
+	}
+	if p.AliveInterval <= 0 {
+		p.AliveInterval = defaultAliveInterval
+	}
+	if p.ActivityGradient == "" {
+		p.ActivityGradient = defaultGradient
+	}
+	if p.StatsBands < 1 {
+		p.StatsBands = defaultStatsBands
+	}
+	return p
+}
+
+// newRunID 根据当前时间和计数器生成一个唯一的运行编号
+// generates a unique run id from the current time and a counter
+func newRunID() string {
+	count := atomic.AddUint64(&runCounter, 1)
+	return strconv.FormatInt(time.Now().Unix(), 36) + "-" + strconv.FormatUint(count, 36)
+}
+
+// expandTemplate 替换文件名模板中的占位符：
+// replaces the placeholders in a filename template:
+//
+//	{turn}      completed turns
+//	{size}      <height>x<width>
+//	{width}     image width
+//	{height}    image height
+//	{rule}      the rule being simulated, e.g. B3S23
+//	{timestamp} the current local time as 20060102-150405
+//	{run}       Params.RunID
+func expandTemplate(template string, p Params, turn int) string {
+	replacer := strings.NewReplacer(
+		"{turn}", strconv.Itoa(turn),
+		"{size}", strconv.Itoa(p.ImageHeight)+"x"+strconv.Itoa(p.ImageWidth),
+		"{width}", strconv.Itoa(p.ImageWidth),
+		"{height}", strconv.Itoa(p.ImageHeight),
+		"{rule}", conwayRule,
+		"{timestamp}", time.Now().Format("20060102-150405"),
+		"{run}", p.RunID,
+	)
+	return replacer.Replace(template)
+}
@@ -1,20 +1,122 @@
 package gol
 
+import (
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/analysis"
+	"uk.ac.bris.cs/gameoflife/patterns"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
 // Params provides the details of how to run the Game of Life and which image to load.
 type Params struct {
 	Turns       int
 	Threads     int
 	ImageWidth  int
 	ImageHeight int
+
+	// Soup generates a random initial world instead of loading the input image when Soup.Density is set.
+	// The generated world is saved to the output directory with "-soup" added to its name.
+	Soup Soup
+
+	// Pattern starts from an empty world with the pattern in its centre instead of loading the input image.
+	Pattern *patterns.Pattern
+
+	// PausedEdits only applies the edits given to RunControlled while the run is paused, discarding
+	// the ones that arrive while it is running, e.g. for edits made by clicking on cells in the SDL window.
+	PausedEdits bool
+
+	// InputDir is the directory the initial image is read from. Defaults to "images".
+	InputDir string
+	// OutputDir is the directory output images are written to. Defaults to "out".
+	OutputDir string
+	// OutputTemplate names output images, see expandTemplate for the placeholders.
+	// Defaults to "{size}x{turn}".
+	OutputTemplate string
+	// RunID is substituted for {run} in OutputTemplate. Run generates one when it is empty.
+	RunID string
+	// ImageFormat is the format of output images, either "pgm" or "png". Defaults to "pgm".
+	ImageFormat string
+	// ImageScale is the size in pixels of each cell in png output. Defaults to 1.
+	ImageScale int
+	// Palette colours the cells of png output and the SDL window. Png images are drawn from the world
+	// alone, so png output needs a palette without age colours. Defaults to util.DefaultPalette.
+	Palette util.Palette
+
+	// SnapshotEvery saves a snapshot of the world every SnapshotEvery turns. 0 disables it.
+	SnapshotEvery int
+	// SnapshotInterval saves a snapshot of the world every SnapshotInterval. 0 disables it.
+	SnapshotInterval time.Duration
+	// SnapshotKeep is the number of most recent snapshots kept on disk. 0 keeps all of them.
+	SnapshotKeep int
+	// SnapshotTemplate names snapshot images like OutputTemplate. Defaults to "snapshot-{size}x{turn}".
+	SnapshotTemplate string
+
+	// Checkpoint is the path a checkpoint is written to with every snapshot and at the end of the run.
+	// Empty disables checkpoints.
+	Checkpoint string
+	// Resume is the path of a checkpoint to resume from instead of loading the input image.
+	Resume string
+
+	// DeltaLog is the path a delta log of the run is written to, see OpenDeltaLog. Empty disables it.
+	DeltaLog string
+
+	// FlipDelivery chooses between CellFlipped events, batched CellsFlipped events or neither
+	// for the events sent by the run itself. Defaults to PerCellFlips.
+	// Runs read through a Hub should use BatchedFlips and let every subscriber choose its own delivery.
+	FlipDelivery FlipDelivery
+
+	// AliveInterval is the interval between AliveCellsCount events. Defaults to 2s.
+	AliveInterval time.Duration
+	// AliveEveryTurns sends AliveCellsCount events every AliveEveryTurns turns instead of every AliveInterval,
+	// so the reports are deterministic. 0 reports by time.
+	AliveEveryTurns int
+
+	// StatsEvery sends a PopulationStats event every StatsEvery turns. 0 disables it.
+	StatsEvery int
+	// StatsBands is the number of horizontal bands in PopulationStats.BandDensity. Defaults to 8.
+	StatsBands int
+
+	// TrackEvery follows the spaceships in the world every TrackEvery turns, sending an ObjectsMoved event.
+	// 0 disables it.
+	TrackEvery int
+
+	// Activity keeps the turn every cell last changed on and how many times it flipped, and saves them
+	// as "-flips" and "-age" png images at the end of the run, showing where the world is still active.
+	Activity bool
+	// ActivityGradient colours the activity images, "grey" or "heat". Defaults to "grey".
+	ActivityGradient string
+
+	// Census takes a census of the objects in the world at the end of the run, see CensusComplete.
+	// A census can also be taken at any time by pressing c.
+	Census bool
+
+	// Compress gzips pgm images (adding .gz to their names), checkpoints and delta logs.
+	// Compressed files are decompressed transparently when they are read.
+	Compress bool
 }
 
 // Run starts the processing of Game of Life. It should initialise channels and goroutines.
 func Run(p Params, events chan<- Event, keyPresses <-chan rune) {
+	RunControlled(p, events, keyPresses, nil)
+}
+
+// RunControlled is Run with a channel of edits, which are applied to the world between turns
+// while running or paused (only while paused with Params.PausedEdits). The cells flipped by each edit are reported like the cells flipped
+// in a turn, followed by an EditComplete event.
+func RunControlled(p Params, events chan<- Event, keyPresses <-chan rune, edits <-chan Edit) {
+	p = withDefaults(p)
+
 	ioCommand := make(chan ioCommand)
 	ioIdle := make(chan bool)
 	ioFilename := make(chan string)
-	ioOutput := make(chan uint8, p.ImageHeight*p.ImageWidth)
-	ioInput := make(chan uint8, p.ImageHeight*p.ImageWidth)
+	ioOutput := make(chan []uint8, 1)
+	ioInput := make(chan []uint8, 1)
+	ioCheckpointOut := make(chan checkpoint, 1)
+	ioCheckpointIn := make(chan checkpoint, 1)
+	ioCensus := make(chan analysis.Census, 1)
+	ioHeatmap := make(chan []float64, 1)
+	done := make(chan bool)
 
 	ioChannels := ioChannels{
 		command:  ioCommand,
@@ -22,6 +124,14 @@ func Run(p Params, events chan<- Event, keyPresses <-chan rune) {
 		filename: ioFilename,
 		output:   ioOutput,
 		input:    ioInput,
+
+		checkpointOut: ioCheckpointOut,
+		checkpointIn:  ioCheckpointIn,
+
+		census:  ioCensus,
+		heatmap: ioHeatmap,
+
+		done: done,
 	}
 	go startIo(p, ioChannels)
 
@@ -32,6 +142,14 @@ func Run(p Params, events chan<- Event, keyPresses <-chan rune) {
 		ioFilename: ioFilename,
 		ioOutput:   ioOutput,
 		ioInput:    ioInput,
+
+		ioCheckpointOut: ioCheckpointOut,
+		ioCheckpointIn:  ioCheckpointIn,
+
+		ioCensus:  ioCensus,
+		ioHeatmap: ioHeatmap,
+
+		done: done,
 	}
-	distributor(p, distributorChannels, keyPresses)
+	distributor(p, distributorChannels, keyPresses, edits)
 }
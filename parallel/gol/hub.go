@@ -0,0 +1,225 @@
+package gol
+
+import (
+	"reflect"
+	"sync"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Policy decides what a subscriber's queue does when an event arrives and the queue is full.
+type Policy int
+
+const (
+	// Unbounded grows the subscriber's queue as needed, so it sees every event without ever
+	// holding up the hub or the run. A subscriber that falls behind only costs memory.
+	Unbounded Policy = iota
+	// Block makes the hub wait until the subscriber has room, so it sees every event with a bounded queue.
+	// This back-pressures the run: while a Block subscriber is full, no subscriber is given new events
+	// and gol.Run waits to send its next event, so opt into it only when slowing the run is wanted.
+	Block
+	// DropOldest drops the oldest queued event to make room for the new one.
+	DropOldest
+	// Coalesce keeps at most one queued event of each type, replacing it with the newest one,
+	// whatever the size of the buffer. It suits consumers that only want the latest state, e.g. the latest AliveCellsCount.
+	Coalesce
+)
+
+// Hub fans the events of a single run out to several subscribers, such as the SDL window,
+// a logger and a metrics collector. Every subscriber has its own queue and goroutine, so a slow
+// subscriber only affects the others if its Policy is Block.
+// Every subscription must be read until it is closed, otherwise its goroutine is never released.
+//
+// The run should send its flipped cells in the canonical form, CellsFlipped events (Params.FlipDelivery
+// set to BatchedFlips), and every subscriber chooses how it receives them when it subscribes.
+// CellFlipped events from the source are batched for subscribers that want CellsFlipped events.
+type Hub struct {
+	source      <-chan Event
+	lock        sync.Mutex
+	subscribers []*subscriber
+}
+
+// subscriber is one consumer of a Hub with its own queue.
+type subscriber struct {
+	filter func(Event) bool
+	flips  FlipDelivery
+	buffer int
+	policy Policy
+	out    chan Event
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	queue  []Event
+	closed bool
+
+	// pending batches the CellFlipped events of a turn for a subscriber wanting BatchedFlips,
+	// it is only used by the hub's goroutine.
+	pending *CellsFlipped
+}
+
+// NewHub creates a hub reading the events sent by gol.Run to source.
+func NewHub(source <-chan Event) *Hub {
+	return &Hub{source: source}
+}
+
+// Subscribe returns a channel receiving the events accepted by filter (nil accepts every event),
+// with the flipped cells delivered as flips chooses: one CellFlipped event per cell, CellsFlipped events or none.
+// Up to buffer events are queued for the subscriber before policy applies, Unbounded ignores buffer.
+// The channel is closed once the run has finished and every queued event has been received.
+func (h *Hub) Subscribe(filter func(Event) bool, flips FlipDelivery, buffer int, policy Policy) <-chan Event {
+	if buffer < 1 {
+		buffer = 1
+	}
+	s := &subscriber{
+		filter: filter,
+		flips:  flips,
+		buffer: buffer,
+		policy: policy,
+		out:    make(chan Event),
+	}
+	s.cond = sync.NewCond(&s.lock)
+	go s.deliver()
+
+	h.lock.Lock()
+	h.subscribers = append(h.subscribers, s)
+	h.lock.Unlock()
+	return s.out
+}
+
+// Run passes every event from the source to the subscribers until the source is closed.
+// It should be started in its own goroutine, like gol.Run.
+func (h *Hub) Run() {
+	for event := range h.source {
+		for _, s := range h.current() {
+			s.send(event)
+		}
+	}
+
+	for _, s := range h.current() {
+		s.flush()
+		s.close()
+	}
+}
+
+// current returns a copy of the subscribers, so events are pushed without holding the hub's lock
+// and a Block subscriber waiting for room does not stop new subscriptions.
+func (h *Hub) current() []*subscriber {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]*subscriber(nil), h.subscribers...)
+}
+
+// EventTypes returns a filter accepting only events of the same types as the given examples,
+// e.g. EventTypes(AliveCellsCount{}, FinalTurnComplete{}).
+func EventTypes(examples ...Event) func(Event) bool {
+	types := make(map[reflect.Type]bool)
+	for _, example := range examples {
+		types[reflect.TypeOf(example)] = true
+	}
+	return func(event Event) bool {
+		return types[reflect.TypeOf(event)]
+	}
+}
+
+// send converts the flipped cells of an event to the subscriber's FlipDelivery and queues the events
+// accepted by its filter.
+func (s *subscriber) send(event Event) {
+	switch e := event.(type) {
+	case CellsFlipped:
+		switch s.flips {
+		case PerCellFlips:
+			s.flush()
+			for _, cell := range e.Cells {
+				s.offer(CellFlipped{CompletedTurns: e.CompletedTurns, Cell: cell})
+			}
+		case BatchedFlips:
+			s.flush()
+			s.offer(e)
+		}
+	case CellFlipped:
+		switch s.flips {
+		case PerCellFlips:
+			s.offer(e)
+		case BatchedFlips:
+			if s.pending != nil && s.pending.CompletedTurns != e.CompletedTurns {
+				s.flush()
+			}
+			if s.pending == nil {
+				s.pending = &CellsFlipped{CompletedTurns: e.CompletedTurns, Cells: []util.Cell{}}
+			}
+			s.pending.Cells = append(s.pending.Cells, e.Cell)
+		}
+	default:
+		s.flush()
+		s.offer(event)
+	}
+}
+
+// flush queues the CellFlipped events batched so far as a single CellsFlipped event.
+func (s *subscriber) flush() {
+	if s.pending != nil {
+		s.offer(*s.pending)
+		s.pending = nil
+	}
+}
+
+// offer queues an event if the subscriber's filter accepts it.
+func (s *subscriber) offer(event Event) {
+	if s.filter == nil || s.filter(event) {
+		s.push(event)
+	}
+}
+
+// push queues an event according to the subscriber's policy.
+func (s *subscriber) push(event Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	switch s.policy {
+	case Block:
+		for len(s.queue) >= s.buffer {
+			s.cond.Wait()
+		}
+	case DropOldest:
+		if len(s.queue) >= s.buffer {
+			s.queue = s.queue[1:]
+		}
+	case Coalesce:
+		for i, queued := range s.queue {
+			if reflect.TypeOf(queued) == reflect.TypeOf(event) {
+				s.queue = append(s.queue[:i], s.queue[i+1:]...)
+				break
+			}
+		}
+	}
+	s.queue = append(s.queue, event)
+	s.cond.Broadcast()
+}
+
+// close marks the end of the events, the output channel is closed once the queue is empty.
+func (s *subscriber) close() {
+	s.lock.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.lock.Unlock()
+}
+
+// deliver sends the queued events to the subscriber's channel in order.
+func (s *subscriber) deliver() {
+	for {
+		s.lock.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 {
+			s.lock.Unlock()
+			close(s.out)
+			return
+		}
+		event := s.queue[0]
+		s.queue = s.queue[1:]
+		s.cond.Broadcast()
+		s.lock.Unlock()
+
+		s.out <- event
+	}
+}
@@ -1,11 +1,15 @@
 package gol
 
 import (
+	"bufio"
 	"fmt"
+	"image/png"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"uk.ac.bris.cs/gameoflife/analysis"
 	"uk.ac.bris.cs/gameoflife/util"
 )
 
@@ -14,8 +18,17 @@ type ioChannels struct {
 	idle    chan<- bool
 
 	filename <-chan string
-	output   <-chan uint8
-	input    chan<- uint8
+	output   <-chan []uint8
+	input    chan<- []uint8
+
+	checkpointOut <-chan checkpoint
+	checkpointIn  chan<- checkpoint
+
+	census  <-chan analysis.Census
+	heatmap <-chan []float64
+
+	// done is closed by the distributor when the run has finished, stopping the io goroutine
+	done <-chan bool
 }
 
 // ioState is the internal ioState of the io goroutine.
@@ -29,70 +42,116 @@ type ioCommand uint8
 
 // This is a way of creating enums in Go.
 // It will evaluate to:
-//		ioOutput 	= 0
-//		ioInput 	= 1
-//		ioCheckIdle = 2
+//
+//	ioOutput 	= 0
+//	ioInput 	= 1
+//	ioCheckIdle = 2
+//	ioRemove 	= 3
+//	ioCheckpoint = 4
+//	ioResume 	= 5
+//	ioCensus 	= 6
+//	ioHeatmap 	= 7
 const (
 	ioOutput ioCommand = iota
 	ioInput
 	ioCheckIdle
+	ioRemove
+	ioCheckpoint
+	ioResume
+	ioCensus
+	ioHeatmap
 )
 
-// writePgmImage receives an array of bytes and writes it to a pgm file.
-func (io *ioState) writePgmImage() {
-	_ = os.Mkdir("out", os.ModePerm)
+// imagePath returns the path of the output image with the given filename.
+func (io *ioState) imagePath(filename string) string {
+	path := filepath.Join(io.params.OutputDir, filename+"."+io.params.ImageFormat)
+	if io.params.Compress && io.params.ImageFormat == "pgm" {
+		path += ".gz"
+	}
+	return path
+}
 
+// writeImage receives the whole frame as a single array of bytes and writes it
+// as a pgm or png file, depending on Params.ImageFormat.
+func (io *ioState) writeImage() {
 	// Request a filename from the distributor.
 	filename := <-io.channels.filename
 
-	file, ioError := os.Create("out/" + filename + ".pgm")
-	util.Check(ioError)
-	defer file.Close()
-
-	_, _ = file.WriteString("P5\n")
-	//_, _ = file.WriteString("# PGM file writer by pnmmodules (https://github.com/owainkenwayucl/pnmmodules).\n")
-	_, _ = file.WriteString(strconv.Itoa(io.params.ImageWidth))
-	_, _ = file.WriteString(" ")
-	_, _ = file.WriteString(strconv.Itoa(io.params.ImageHeight))
-	_, _ = file.WriteString("\n")
-	_, _ = file.WriteString(strconv.Itoa(255))
-	_, _ = file.WriteString("\n")
-
-	world := make([][]byte, io.params.ImageHeight)
-	for i := range world {
-		world[i] = make([]byte, io.params.ImageWidth)
+	frame := <-io.channels.output
+	if len(frame) != io.params.ImageWidth*io.params.ImageHeight {
+		panic("Incorrect frame size")
 	}
 
-	for y := 0; y < io.params.ImageHeight; y++ {
-		for x := 0; x < io.params.ImageWidth; x++ {
-			val := <-io.channels.output
-			//if val != 0 {
-			//	fmt.Println(x, y)
-			//}
-			world[y][x] = val
+	switch io.params.ImageFormat {
+	case "png":
+		// png图像只根据世界绘制，无法按年龄着色
+		//png images are drawn from the world alone, so they cannot colour cells by age
+		if io.params.Palette.Aged() {
+			panic("Png output cannot colour cells by age, use a palette without age colours")
 		}
+		// png已经是压缩格式，不再额外压缩
+		//png is already compressed, so it is never gzipped
+		writeAtomic(io.imagePath(filename), false, func(writer *bufio.Writer) error {
+			return io.writePngImage(writer, frame)
+		})
+	case "pgm":
+		writeAtomic(io.imagePath(filename), io.params.Compress, func(writer *bufio.Writer) error {
+			return io.writePgmImage(writer, frame)
+		})
+	default:
+		panic("Unknown image format " + io.params.ImageFormat)
 	}
 
-	for y := 0; y < io.params.ImageHeight; y++ {
-		for x := 0; x < io.params.ImageWidth; x++ {
-			_, ioError = file.Write([]byte{world[y][x]})
-			util.Check(ioError)
-		}
-	}
+	fmt.Println("File", filename, "output done!")
+}
 
-	ioError = file.Sync()
-	util.Check(ioError)
+// writePgmImage writes the frame to writer as a pgm image.
+func (io *ioState) writePgmImage(writer *bufio.Writer, frame []uint8) error {
+	_, _ = writer.WriteString("P5\n")
+	//_, _ = writer.WriteString("# PGM file writer by pnmmodules (https://github.com/owainkenwayucl/pnmmodules).\n")
+	_, _ = writer.WriteString(strconv.Itoa(io.params.ImageWidth))
+	_, _ = writer.WriteString(" ")
+	_, _ = writer.WriteString(strconv.Itoa(io.params.ImageHeight))
+	_, _ = writer.WriteString("\n")
+	_, _ = writer.WriteString(strconv.Itoa(255))
+	_, _ = writer.WriteString("\n")
+	_, ioError := writer.Write(frame)
+	return ioError
+}
 
-	fmt.Println("File", filename, "output done!")
+// writePngImage writes the frame to writer as a png image, scaled by Params.ImageScale and coloured by Params.Palette.
+func (io *ioState) writePngImage(writer *bufio.Writer, frame []uint8) error {
+	img := util.FrameImage(frame, io.params.ImageWidth, io.params.ImageHeight, io.params.ImageScale, io.params.Palette)
+	return png.Encode(writer, img)
+}
+
+// removeImage removes a previously written output image.
+func (io *ioState) removeImage() {
+	// Request a filename from the distributor.
+	filename := <-io.channels.filename
+
+	ioError := os.Remove(io.imagePath(filename))
+	if ioError != nil && !os.IsNotExist(ioError) {
+		util.Check(ioError)
+	}
 }
 
-// readPgmImage opens a pgm file and sends its data as an array of bytes.
+// readPgmImage opens a pgm file and sends its data as a single array of bytes.
+// When there is no .pgm file, a gzip compressed .pgm.gz file is read instead.
 func (io *ioState) readPgmImage() {
 
 	// Request a filename from the distributor.
 	filename := <-io.channels.filename
 
-	data, ioError := ioutil.ReadFile("images/" + filename + ".pgm")
+	path := filepath.Join(io.params.InputDir, filename+".pgm")
+	if _, ioError := os.Stat(path); os.IsNotExist(ioError) {
+		path += ".gz"
+	}
+	file, ioError := openDecompressed(path)
+	util.Check(ioError)
+	data, ioError := ioutil.ReadAll(file)
+	util.Check(ioError)
+	ioError = file.Close()
 	util.Check(ioError)
 
 	fields := strings.Fields(string(data))
@@ -117,11 +176,12 @@ func (io *ioState) readPgmImage() {
 	}
 
 	image := []byte(fields[4])
-
-	for _, b := range image {
-		io.channels.input <- b
+	if len(image) != width*height {
+		panic("Incorrect image size")
 	}
 
+	io.channels.input <- image
+
 	fmt.Println("File", filename, "input done!")
 }
 
@@ -135,14 +195,26 @@ func startIo(p Params, c ioChannels) {
 	for {
 		select {
 		// Block and wait for requests from the distributor
+		case <-io.channels.done:
+			return
 		case command := <-io.channels.command:
 			switch command {
 			case ioInput:
 				io.readPgmImage()
 			case ioOutput:
-				io.writePgmImage()
+				io.writeImage()
 			case ioCheckIdle:
 				io.channels.idle <- true
+			case ioRemove:
+				io.removeImage()
+			case ioCheckpoint:
+				io.writeCheckpoint()
+			case ioResume:
+				io.readCheckpoint()
+			case ioCensus:
+				io.writeCensus()
+			case ioHeatmap:
+				io.writeHeatmap()
 			}
 		}
 	}
@@ -0,0 +1,129 @@
+package gol
+
+import (
+	"image"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// populationTracker 根据每回合翻转的细胞增量地维护每行和每列的存活细胞数，
+// 所以生成 PopulationStats 不需要扫描整个世界
+// keeps the number of alive cells in every row and column up to date from the cells flipped in each turn,
+// so PopulationStats never needs to scan the whole world
+type populationTracker struct {
+	every, bands   int
+	width, height  int
+	rowAlive       []int
+	columnAlive    []int
+	alive          int
+	births, deaths int
+	previousAlive  int
+}
+
+// newPopulationTracker 根据初始存活的细胞创建统计器，Params.StatsEvery 未设置时返回 nil
+// creates a tracker from the initially alive cells, returns nil when Params.StatsEvery is not set
+func newPopulationTracker(p Params, aliveCells []util.Cell) *populationTracker {
+	if p.StatsEvery <= 0 {
+		return nil
+	}
+	t := &populationTracker{
+		every:       p.StatsEvery,
+		bands:       p.StatsBands,
+		width:       p.ImageWidth,
+		height:      p.ImageHeight,
+		rowAlive:    make([]int, p.ImageHeight),
+		columnAlive: make([]int, p.ImageWidth),
+	}
+	for _, cell := range aliveCells {
+		t.rowAlive[cell.Y]++
+		t.columnAlive[cell.X]++
+	}
+	t.alive = len(aliveCells)
+	t.previousAlive = t.alive
+	return t
+}
+
+// apply 在世界更新后统计翻转的细胞中的出生和死亡
+// counts the births and deaths among the flipped cells, after the world has been updated
+func (t *populationTracker) apply(world [][]uint8, flippedCells []util.Cell) {
+	if t == nil {
+		return
+	}
+	for _, cell := range flippedCells {
+		change := -1
+		if world[cell.Y][cell.X] == 255 {
+			change = 1
+			t.births++
+		} else {
+			t.deaths++
+		}
+		t.rowAlive[cell.Y] += change
+		t.columnAlive[cell.X] += change
+		t.alive += change
+	}
+}
+
+// due 判断完成指定回合后是否需要报告
+// reports whether a report is due after completing the given turn
+func (t *populationTracker) due(turn int) bool {
+	return t != nil && turn%t.every == 0
+}
+
+// report 生成自上次报告以来的统计，并重新开始计数
+// produces the statistics since the previous report and starts counting again
+func (t *populationTracker) report(turn int) PopulationStats {
+	stats := PopulationStats{
+		CompletedTurns: turn,
+		Alive:          t.alive,
+		Births:         t.births,
+		Deaths:         t.deaths,
+		Change:         t.alive - t.previousAlive,
+		Bounds:         t.bounds(),
+		BandDensity:    make([]float64, t.bands),
+	}
+	// 将行平均分配到各个带中，除不尽的行分配到前几个带
+	//rows are divided evenly between the bands, the remaining rows go to the first few bands
+	row := 0
+	for band := 0; band < t.bands; band++ {
+		size := t.height / t.bands
+		if band < t.height%t.bands {
+			size++
+		}
+		bandAlive := 0
+		for y := row; y < row+size; y++ {
+			bandAlive += t.rowAlive[y]
+		}
+		if size > 0 {
+			stats.BandDensity[band] = float64(bandAlive) / float64(size*t.width)
+		}
+		row += size
+	}
+	t.births, t.deaths = 0, 0
+	t.previousAlive = t.alive
+	return stats
+}
+
+// bounds 根据每行和每列的存活细胞数找到包含所有存活细胞的最小矩形
+// finds the smallest rectangle containing every alive cell from the row and column counts
+func (t *populationTracker) bounds() image.Rectangle {
+	first := func(counts []int) int {
+		for i, count := range counts {
+			if count > 0 {
+				return i
+			}
+		}
+		return -1
+	}
+	last := func(counts []int) int {
+		for i := len(counts) - 1; i >= 0; i-- {
+			if counts[i] > 0 {
+				return i
+			}
+		}
+		return -1
+	}
+	if t.alive == 0 {
+		return image.Rectangle{}
+	}
+	return image.Rect(first(t.columnAlive), first(t.rowAlive), last(t.columnAlive)+1, last(t.rowAlive)+1)
+}
@@ -0,0 +1,63 @@
+package gol
+
+import "time"
+
+// snapshotter 决定何时保存快照，并记录已保存的快照以便只保留最近的几个
+// decides when snapshots are due and remembers the saved ones, so only the most recent are kept
+type snapshotter struct {
+	every     int
+	tick      <-chan time.Time
+	ticker    *time.Ticker
+	keep      int
+	filenames []string // oldest first
+}
+
+// newSnapshotter 根据参数创建快照器，未设置时间间隔时不创建ticker
+// creates a snapshotter from the params, without a ticker when no interval is set
+func newSnapshotter(p Params) *snapshotter {
+	s := &snapshotter{every: p.SnapshotEvery, keep: p.SnapshotKeep}
+	if p.SnapshotInterval > 0 {
+		s.ticker = time.NewTicker(p.SnapshotInterval)
+		s.tick = s.ticker.C
+	}
+	return s
+}
+
+// due 在完成指定回合后判断是否需要保存快照
+// reports whether a snapshot should be saved after completing the given turn
+func (s *snapshotter) due(turn int) bool {
+	if s.every > 0 && turn%s.every == 0 {
+		return true
+	}
+	select {
+	case <-s.tick:
+		return true
+	default:
+		return false
+	}
+}
+
+// output 通过outputPGM保存快照，并删除超出保留数量的最旧快照
+// saves a snapshot through outputPGM and removes the oldest snapshots beyond the number kept
+func (s *snapshotter) output(c distributorChannels, p Params, turn int, frame []uint8) {
+	filename := expandTemplate(p.SnapshotTemplate, p, turn)
+	outputPGM(c, filename, turn, frame)
+	// 模板中没有{turn}时快照会覆盖同一个文件，不需要重复记录
+	//without {turn} in the template every snapshot overwrites the same file, so it is only recorded once
+	if len(s.filenames) == 0 || s.filenames[len(s.filenames)-1] != filename {
+		s.filenames = append(s.filenames, filename)
+	}
+	for s.keep > 0 && len(s.filenames) > s.keep {
+		c.ioCommand <- ioRemove
+		c.ioFilename <- s.filenames[0]
+		s.filenames = s.filenames[1:]
+	}
+}
+
+// stop 停止时间间隔ticker
+// stops the interval ticker
+func (s *snapshotter) stop() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+}
@@ -0,0 +1,40 @@
+package gol
+
+import (
+	"math/rand"
+)
+
+// Soup describes a random initial world, generated instead of loading the input image.
+type Soup struct {
+	// Density is the probability that a cell is alive, between 0 and 1. 0 disables the soup.
+	Density float64
+	// Seed seeds the random generator, so the same seed always generates the same world.
+	Seed int64
+	// Size fills only a centred Size x Size region of the world. 0 fills the whole world.
+	Size int
+}
+
+// generateSoup 根据 Params.Soup 生成随机的世界，从世界的左上角到右下角
+// generates a random world from Params.Soup, from the top left to the bottom right corner
+func generateSoup(p Params) []uint8 {
+	frame := make([]uint8, p.ImageWidth*p.ImageHeight)
+	width, height := p.ImageWidth, p.ImageHeight
+	if p.Soup.Size > 0 && p.Soup.Size < width {
+		width = p.Soup.Size
+	}
+	if p.Soup.Size > 0 && p.Soup.Size < height {
+		height = p.Soup.Size
+	}
+	left := (p.ImageWidth - width) / 2
+	top := (p.ImageHeight - height) / 2
+
+	random := rand.New(rand.NewSource(p.Soup.Seed))
+	for y := top; y < top+height; y++ {
+		for x := left; x < left+width; x++ {
+			if random.Float64() < p.Soup.Density {
+				frame[y*p.ImageWidth+x] = 255
+			}
+		}
+	}
+	return frame
+}
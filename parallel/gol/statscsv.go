@@ -0,0 +1,35 @@
+package gol
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// WriteStatsCSV writes a row of completed turns, alive cells, births and deaths for every
+// PopulationStats event from events until it is closed. The first two columns match the
+// tables in check/alive, so they can be regenerated with it. events is typically a Hub subscription.
+func WriteStatsCSV(events <-chan Event, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"completed_turns", "alive_cells", "births", "deaths"})
+	if err != nil {
+		return err
+	}
+	for event := range events {
+		stats, ok := event.(PopulationStats)
+		if !ok {
+			continue
+		}
+		err = writer.Write([]string{
+			strconv.Itoa(stats.CompletedTurns),
+			strconv.Itoa(stats.Alive),
+			strconv.Itoa(stats.Births),
+			strconv.Itoa(stats.Deaths),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
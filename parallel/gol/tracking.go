@@ -0,0 +1,82 @@
+package gol
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"uk.ac.bris.cs/gameoflife/analysis"
+)
+
+// objectTracker 每 Params.TrackEvery 回合找出世界中的飞船，并跟踪它们的移动
+// finds the spaceships in the world every Params.TrackEvery turns and follows their movement
+type objectTracker struct {
+	every   int
+	tracker *analysis.Tracker
+}
+
+// newObjectTracker 从初始世界开始跟踪，Params.TrackEvery 未设置时返回 nil
+// starts tracking from the initial world (see worldToFrame), returns nil when Params.TrackEvery is not set
+func newObjectTracker(p Params, turn int, frame []uint8) *objectTracker {
+	if p.TrackEvery <= 0 {
+		return nil
+	}
+	t := &objectTracker{every: p.TrackEvery, tracker: analysis.NewTracker(p.ImageWidth, p.ImageHeight)}
+	t.tracker.Update(turn, analysis.Objects(frame, p.ImageWidth, p.ImageHeight))
+	return t
+}
+
+// due 判断完成turn回合后是否需要更新
+// reports whether the tracker should be updated after turn turns
+func (t *objectTracker) due(turn int) bool {
+	return t != nil && turn%t.every == 0
+}
+
+// report 找出世界副本中的飞船，与上次更新时的飞船匹配，并生成 ObjectsMoved 事件，不需要持有processLock
+// finds the spaceships in a copy of the world (see worldToFrame), matches them to the ones found by the previous update
+// and creates an ObjectsMoved event, without needing processLock
+func (t *objectTracker) report(p Params, turn int, frame []uint8) ObjectsMoved {
+	moves, collisions := t.tracker.Update(turn, analysis.Objects(frame, p.ImageWidth, p.ImageHeight))
+	return ObjectsMoved{CompletedTurns: turn, Moves: moves, Collisions: collisions}
+}
+
+// WriteTrackSummary follows the ObjectsMoved events from events until it is closed, then writes
+// a table with a row for every spaceship: where it was first and last seen, its velocity and heading,
+// and where it hit another object. events is typically a Hub subscription.
+func WriteTrackSummary(events <-chan Event, w io.Writer) error {
+	last := map[int]analysis.Movement{}
+	lastTurn := map[int]int{}
+	collided := map[int]string{}
+	for event := range events {
+		moved, ok := event.(ObjectsMoved)
+		if !ok {
+			continue
+		}
+		for _, move := range moved.Moves {
+			last[move.ID] = move
+			lastTurn[move.ID] = moved.CompletedTurns
+		}
+		for _, collision := range moved.Collisions {
+			collided[collision.ID] = fmt.Sprintf("hit %v at %v on turn %v", collision.Debris, collision.Position, moved.CompletedTurns)
+		}
+	}
+
+	ids := make([]int, 0, len(last))
+	for id := range last {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "id\tobject\tfirst turn\tlast turn\tstart\tend\tvelocity\theading\tfate")
+	for _, id := range ids {
+		move := last[id]
+		fate, ok := collided[id]
+		if !ok {
+			fate = "moving"
+		}
+		_, _ = fmt.Fprintf(table, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			id, move.Name, move.FirstTurn, lastTurn[id], move.Start, move.Position, move.Velocity, move.Heading, fate)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestHub tests that every subscriber of a hub gets its events, and that subscribers
+// which never read do not stall the run unless their policy is Block.
+func TestHub(t *testing.T) {
+	p := gol.Params{Turns: 100, Threads: 8, ImageWidth: 64, ImageHeight: 64, OutputDir: t.TempDir()}
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+
+	hub := gol.NewHub(events)
+	all := hub.Subscribe(nil, gol.PerCellFlips, 10, gol.Block)
+	turns := hub.Subscribe(gol.EventTypes(gol.TurnComplete{}), gol.NoFlips, 1, gol.DropOldest)
+	latest := hub.Subscribe(gol.EventTypes(gol.TurnComplete{}, gol.FinalTurnComplete{}), gol.NoFlips, 1, gol.Coalesce)
+	unread := hub.Subscribe(nil, gol.PerCellFlips, 1, gol.DropOldest) // not read until the run is over
+	go hub.Run()
+
+	done := make(chan bool)
+	go func() {
+		timer := time.After(10 * time.Second)
+		select {
+		case <-timer:
+			t.Error("the run was stalled by a subscriber that never reads")
+		case <-done:
+		}
+	}()
+
+	turnCompletes := 0
+	final := false
+	for event := range all {
+		switch event.(type) {
+		case gol.TurnComplete:
+			turnCompletes++
+		case gol.FinalTurnComplete:
+			final = true
+		}
+	}
+	close(done)
+	if turnCompletes != p.Turns || !final {
+		t.Errorf("Block subscriber received %v TurnComplete events and FinalTurnComplete %v", turnCompletes, final)
+	}
+
+	// DropOldest只保留最后一个回合 DropOldest only keeps the last turn
+	var last gol.Event
+	for event := range turns {
+		last = event
+	}
+	if last == nil || last.GetCompletedTurns() != p.Turns {
+		t.Errorf("DropOldest subscriber should end with TurnComplete %v, got %v", p.Turns, last)
+	}
+
+	// Coalesce每种类型只保留最新的一个 Coalesce keeps only the newest event of each type
+	var coalesced []gol.Event
+	for event := range latest {
+		coalesced = append(coalesced, event)
+	}
+	if len(coalesced) > 3 {
+		t.Errorf("Coalesce subscriber should have at most 3 events queued, got %v", len(coalesced))
+	}
+
+	// 读完剩下的事件，释放订阅者的goroutine
+	//read the remaining events so the subscriber's goroutine is released
+	for range unread {
+	}
+}
+
+// TestHubStalledSubscriber tests that a subscriber which stops reading does not delay the other
+// subscribers or the end of the run, and that it still gets every event once it reads again.
+func TestHubStalledSubscriber(t *testing.T) {
+	p := gol.Params{Turns: 100, Threads: 8, ImageWidth: 64, ImageHeight: 64, OutputDir: t.TempDir()}
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+
+	hub := gol.NewHub(events)
+	final := hub.Subscribe(gol.EventTypes(gol.FinalTurnComplete{}), gol.NoFlips, 0, gol.Unbounded)
+	stalled := hub.Subscribe(nil, gol.PerCellFlips, 0, gol.Unbounded)
+	go hub.Run()
+
+	select {
+	case event := <-final:
+		if event.GetCompletedTurns() != p.Turns {
+			t.Errorf("Expected FinalTurnComplete after %v turns, got %v", p.Turns, event)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("FinalTurnComplete was delayed by a subscriber that stopped reading")
+	}
+
+	turnCompletes := 0
+	for event := range stalled {
+		if _, ok := event.(gol.TurnComplete); ok {
+			turnCompletes++
+		}
+	}
+	if turnCompletes != p.Turns {
+		t.Errorf("Stalled subscriber received %v TurnComplete events, expected %v", turnCompletes, p.Turns)
+	}
+	for range final {
+	}
+}
+
+// TestHubFlips tests that the hub gives every subscriber the flipped cells in the form it chose.
+func TestHubFlips(t *testing.T) {
+	p := gol.Params{Turns: 20, Threads: 4, ImageWidth: 16, ImageHeight: 16, OutputDir: t.TempDir(), FlipDelivery: gol.BatchedFlips}
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+
+	hub := gol.NewHub(events)
+	perCell := hub.Subscribe(nil, gol.PerCellFlips, 0, gol.Unbounded)
+	batched := hub.Subscribe(nil, gol.BatchedFlips, 0, gol.Unbounded)
+	none := hub.Subscribe(nil, gol.NoFlips, 0, gol.Unbounded)
+	go hub.Run()
+
+	// 每个订阅者都根据翻转事件重建世界
+	//every subscriber rebuilds the world from the flip events
+	rebuild := func(events <-chan gol.Event) (map[util.Cell]bool, []util.Cell, int) {
+		alive := map[util.Cell]bool{}
+		var final []util.Cell
+		flipEvents := 0
+		for event := range events {
+			switch e := event.(type) {
+			case gol.CellFlipped:
+				flipEvents++
+				alive[e.Cell] = !alive[e.Cell]
+			case gol.CellsFlipped:
+				flipEvents++
+				for _, cell := range e.Cells {
+					alive[cell] = !alive[cell]
+				}
+			case gol.FinalTurnComplete:
+				final = e.Alive
+			}
+		}
+		for cell, isAlive := range alive {
+			if !isAlive {
+				delete(alive, cell)
+			}
+		}
+		return alive, final, flipEvents
+	}
+	results := make(chan map[util.Cell]bool, 2)
+	go func() {
+		alive, _, _ := rebuild(perCell)
+		results <- alive
+	}()
+	go func() {
+		alive, _, flipEvents := rebuild(batched)
+		if flipEvents != p.Turns+1 {
+			t.Errorf("Expected %v CellsFlipped events, got %v", p.Turns+1, flipEvents)
+		}
+		results <- alive
+	}()
+	_, final, flipEvents := rebuild(none)
+	if flipEvents != 0 {
+		t.Errorf("Expected no flip events with NoFlips, got %v", flipEvents)
+	}
+	for i := 0; i < 2; i++ {
+		alive := <-results
+		if len(alive) != len(final) {
+			t.Errorf("Expected %v alive cells, got %v", len(final), len(alive))
+		}
+		for _, cell := range final {
+			if !alive[cell] {
+				t.Errorf("Expected cell %v to be alive", cell)
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package lifespan measures how long a pattern takes to stabilise, like the methuselahs
+// R-pentomino and acorn, by running it on the engine until its world becomes periodic.
+package lifespan
+
+import (
+	"fmt"
+	"image"
+
+	"uk.ac.bris.cs/gameoflife/analysis"
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/patterns"
+)
+
+const (
+	// escapeMargin is how far a spaceship moving away from the rest of the world must be to count as emitted.
+	escapeMargin = 8
+	// clearMargin is how far around an emitted spaceship is cleared, since it keeps moving until it is removed.
+	clearMargin = 3
+)
+
+// Result describes how a pattern stabilised. Emitted spaceships are removed from the world
+// as they leave, so they are not part of the stabilised pattern.
+type Result struct {
+	// Stabilised reports whether the pattern became periodic within the turns of the run.
+	Stabilised bool
+	// Generation is the first generation of the periodic pattern, or the number of turns run when it did not stabilise.
+	Generation int
+	// Period is the period of the stabilised pattern, 1 for still lifes.
+	Period int
+	// Population is the number of alive cells at Generation, without the emitted spaceships.
+	Population int
+	// Gliders and Spaceships count the emitted gliders and other spaceships.
+	Gliders, Spaceships int
+}
+
+func (r Result) String() string {
+	if !r.Stabilised {
+		return fmt.Sprintf("not stabilised after %v generations, population %v, %v gliders and %v other spaceships emitted",
+			r.Generation, r.Population, r.Gliders, r.Spaceships)
+	}
+	return fmt.Sprintf("stabilised at generation %v with period %v, population %v, %v gliders and %v other spaceships emitted",
+		r.Generation, r.Period, r.Population, r.Gliders, r.Spaceships)
+}
+
+// phase is a tracked spaceship after one turn.
+type phase struct {
+	turn  int
+	hash  uint64
+	cells int
+}
+
+// measurement follows a run and keeps the hash and population of the world after every turn,
+// without the spaceships that are emitted.
+type measurement struct {
+	p        gol.Params
+	edits    chan<- gol.Edit
+	detector *gol.CycleDetector
+	tracker  *analysis.Tracker
+
+	hashes      []uint64
+	populations []int
+	// history holds the hash of every tracked spaceship after every turn, to take them out of the world
+	history  map[int][]phase
+	removing map[int]bool
+	result   Result
+}
+
+// Measure runs pattern in the centre of an empty world with the size, turns and threads in p,
+// until it becomes periodic or p.Turns turns have completed.
+// The world should be large enough for the pattern to stabilise without wrapping around its edges.
+func Measure(p gol.Params, pattern patterns.Pattern) Result {
+	p.Pattern = &pattern
+	p.FlipDelivery = gol.BatchedFlips
+	p.AliveEveryTurns = 1
+	// The events channel is unbuffered, so edits are applied soon after the turn they were decided on.
+	events := make(chan gol.Event)
+	keyPresses := make(chan rune, 1)
+	edits := make(chan gol.Edit, 100)
+	go gol.RunControlled(p, events, keyPresses, edits)
+
+	m := &measurement{
+		p:        p,
+		edits:    edits,
+		detector: gol.NewCycleDetector(p),
+		tracker:  analysis.NewTracker(p.ImageWidth, p.ImageHeight),
+		history:  map[int][]phase{},
+		removing: map[int]bool{},
+	}
+	for event := range events {
+		if m.handle(event) {
+			keyPresses <- 'q'
+		}
+	}
+	return m.result
+}
+
+// handle follows a single event, reporting true once the world has become periodic.
+func (m *measurement) handle(event gol.Event) bool {
+	found := m.detector.Handle(event)
+	switch e := event.(type) {
+	case gol.CellsFlipped:
+		if len(m.hashes) == 0 {
+			// The initial world.
+			m.hashes = append(m.hashes, m.detector.Hash())
+			m.populations = append(m.populations, len(e.Cells))
+			m.follow(e.CompletedTurns)
+		}
+	case gol.TurnComplete:
+		m.hashes = append(m.hashes, m.detector.Hash())
+		m.follow(e.CompletedTurns)
+		if found {
+			m.stabilised()
+		}
+	case gol.AliveCellsCount:
+		m.populations = append(m.populations, e.CellsCount)
+	case gol.FinalTurnComplete:
+		if !m.result.Stabilised {
+			m.result.Generation = e.CompletedTurns
+			m.result.Population = len(e.Alive)
+		}
+	}
+	return found
+}
+
+// follow tracks the spaceships in the world after turn turns, and removes the ones leaving the rest of the world.
+func (m *measurement) follow(turn int) {
+	objects := analysis.Objects(m.detector.Frame(), m.p.ImageWidth, m.p.ImageHeight)
+	moves, collisions := m.tracker.Update(turn, objects)
+
+	for _, collision := range collisions {
+		if m.removing[collision.ID] {
+			// The spaceship was removed, so take it out of the world for every turn before.
+			for _, ph := range m.history[collision.ID] {
+				m.hashes[ph.turn] ^= ph.hash
+				m.populations[ph.turn] -= ph.cells
+			}
+			if collision.Name == "glider" {
+				m.result.Gliders++
+			} else {
+				m.result.Spaceships++
+			}
+			delete(m.removing, collision.ID)
+		}
+		delete(m.history, collision.ID)
+	}
+
+	var rest image.Rectangle
+	spaceships := map[image.Point]analysis.Object{}
+	for _, object := range objects {
+		if object.Kind == analysis.Spaceship {
+			spaceships[object.Bounds.Min] = object
+		} else {
+			rest = rest.Union(object.Bounds)
+		}
+	}
+	for _, move := range moves {
+		object := spaceships[move.Position]
+		m.history[move.ID] = append(m.history[move.ID], phase{turn: turn, hash: m.detector.HashCells(object.Cells), cells: len(object.Cells)})
+		if !m.removing[move.ID] && escaping(move, object.Bounds, rest, turn) {
+			m.removing[move.ID] = true
+			m.edits <- gol.Clear{Rect: object.Bounds.Inset(-clearMargin)}
+		}
+	}
+}
+
+// escaping reports whether a spaceship is moving away from the rest of the world and is far enough from it.
+func escaping(move analysis.Movement, bounds, rest image.Rectangle, turn int) bool {
+	if turn-move.FirstTurn < 4 || move.Distance == (image.Point{}) {
+		return false
+	}
+	if rest.Empty() {
+		return true
+	}
+	gap := maxInt(rest.Min.X-bounds.Max.X, bounds.Min.X-rest.Max.X, rest.Min.Y-bounds.Max.Y, bounds.Min.Y-rest.Max.Y)
+	if gap < escapeMargin {
+		return false
+	}
+	centre := bounds.Min.Add(bounds.Max).Sub(rest.Min.Add(rest.Max))
+	return centre.X*move.Distance.X >= 0 && centre.Y*move.Distance.Y >= 0
+}
+
+// stabilised finds the first generation of the periodic world without the emitted spaceships.
+func (m *measurement) stabilised() {
+	period := m.detector.Period()
+	last := len(m.hashes) - 1
+	generation := last - period
+	for generation > 0 && m.hashes[generation-1] == m.hashes[generation-1+period] {
+		generation--
+	}
+	m.result.Stabilised = true
+	m.result.Generation = generation
+	m.result.Period = period
+	m.result.Population = m.populations[generation]
+}
+
+func maxInt(values ...int) int {
+	max := values[0]
+	for _, value := range values[1:] {
+		if value > max {
+			max = value
+		}
+	}
+	return max
+}
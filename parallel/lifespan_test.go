@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/lifespan"
+	"uk.ac.bris.cs/gameoflife/patterns"
+)
+
+// TestLifespan tests the stabilisation of known methuselahs, with their gliders removed as they leave.
+func TestLifespan(t *testing.T) {
+	tests := []struct {
+		name   string
+		result lifespan.Result
+	}{
+		{"diehard", lifespan.Result{Stabilised: true, Generation: 130, Period: 1}},
+		{"r-pentomino", lifespan.Result{Stabilised: true, Generation: 1103, Period: 2, Population: 86, Gliders: 6}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pattern, err := patterns.Named(test.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := gol.Params{
+				Turns:       5000,
+				Threads:     4,
+				ImageWidth:  256,
+				ImageHeight: 256,
+				InputDir:    t.TempDir(),
+				OutputDir:   t.TempDir(),
+			}
+			result := lifespan.Measure(p, pattern)
+			if result != test.result {
+				t.Errorf("Expected %v, got %v", test.result, result)
+			}
+		})
+	}
+
+	// 在回合用完前没有稳定下来
+	//not stabilised before the turns run out
+	pattern, _ := patterns.Named("r-pentomino")
+	p := gol.Params{Turns: 100, Threads: 2, ImageWidth: 64, ImageHeight: 64, InputDir: t.TempDir(), OutputDir: t.TempDir()}
+	result := lifespan.Measure(p, pattern)
+	if result.Stabilised || result.Generation != 100 {
+		t.Errorf("Expected no stabilisation after 100 generations, got %v", result)
+	}
+}
@@ -3,10 +3,20 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"uk.ac.bris.cs/gameoflife/export"
 	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/lifespan"
+	"uk.ac.bris.cs/gameoflife/patterns"
 	"uk.ac.bris.cs/gameoflife/sdl"
+	"uk.ac.bris.cs/gameoflife/terminal"
+	"uk.ac.bris.cs/gameoflife/util"
 )
 
 // main is the function called when starting Game of Life with 'go run .'
@@ -38,31 +48,427 @@ func main() {
 		10000000000,
 		"Specify the number of turns to process. Defaults to 10000000000.")
 
+	random := flag.String(
+		"random",
+		"",
+		"Generate a random world instead of loading an image, as density,seed, e.g. 0.3,42. A seed is picked when it is left out.")
+
+	flag.IntVar(
+		&params.Soup.Size,
+		"soup-size",
+		0,
+		"Only fill a centred n x n region of the world with -random. Defaults to 0, which fills the whole world.")
+
+	patternName := flag.String(
+		"pattern",
+		"",
+		"Start from a pattern in the centre of an empty world instead of loading an image, as the name of a built-in pattern or the path of an rle file.")
+
+	measure := flag.Bool(
+		"lifespan",
+		false,
+		"Measure how long the -pattern takes to stabilise and print the result instead of running the visualisation.")
+
+	flag.StringVar(
+		&params.InputDir,
+		"in",
+		"images",
+		"Specify the directory the input image is read from. Defaults to images.")
+
+	flag.StringVar(
+		&params.OutputDir,
+		"out",
+		"out",
+		"Specify the directory output images are written to. Defaults to out.")
+
+	flag.StringVar(
+		&params.OutputTemplate,
+		"name",
+		"{size}x{turn}",
+		"Specify the output filename template. Placeholders: {turn}, {size}, {width}, {height}, {rule}, {timestamp}, {run}. Defaults to {size}x{turn}.")
+
+	flag.StringVar(
+		&params.RunID,
+		"run",
+		"",
+		"Specify the run id used for {run} in the filename template. Generated when empty.")
+
+	flag.StringVar(
+		&params.ImageFormat,
+		"format",
+		"pgm",
+		"Specify the format of output images, pgm or png. Defaults to pgm.")
+
+	flag.IntVar(
+		&params.ImageScale,
+		"scale",
+		1,
+		"Specify the size in pixels of each cell in png and gif output. Defaults to 1.")
+
+	paletteName := flag.String(
+		"palette",
+		"mono",
+		"Specify the palette of the window and of png and gif output: mono, inverted, green, amber, or fire and ocean which colour cells by age (not in png output). "+
+			"Custom palettes are dead,alive or dead,alive,newborn,young,old,trail colours, e.g. #000000,#FFFFFF. Defaults to mono.")
+
+	gifPath := flag.String(
+		"gif",
+		"",
+		"Record the run as an animated gif at the given path.")
+
+	var gifOptions export.GIFOptions
+	flag.IntVar(
+		&gifOptions.From,
+		"gif-from",
+		1,
+		"Specify the first turn recorded in the gif, 0 records the initial world. Defaults to 1.")
+
+	flag.IntVar(
+		&gifOptions.To,
+		"gif-to",
+		0,
+		"Specify the last turn recorded in the gif, 0 records until the end. Defaults to 0.")
+
+	flag.IntVar(
+		&gifOptions.Stride,
+		"gif-stride",
+		1,
+		"Record every n-th turn in the gif. Defaults to 1.")
+
+	flag.IntVar(
+		&gifOptions.Delay,
+		"gif-delay",
+		10,
+		"Specify the delay between gif frames in 100ths of a second. Defaults to 10.")
+
+	flag.IntVar(
+		&gifOptions.MaxFrames,
+		"gif-max-frames",
+		export.DefaultMaxFrames,
+		"Specify the most frames kept in the gif, later turns are left out. Defaults to 1000.")
+
+	flag.IntVar(
+		&params.SnapshotEvery,
+		"snapshot-every",
+		0,
+		"Save a snapshot of the world every n turns. Defaults to 0, which disables it.")
+
+	flag.DurationVar(
+		&params.SnapshotInterval,
+		"snapshot-interval",
+		0,
+		"Save a snapshot of the world at the given interval, e.g. 5m. Defaults to 0, which disables it.")
+
+	flag.IntVar(
+		&params.SnapshotKeep,
+		"snapshot-keep",
+		0,
+		"Specify the number of most recent snapshots to keep. Defaults to 0, which keeps all of them.")
+
+	flag.StringVar(
+		&params.Checkpoint,
+		"checkpoint",
+		"",
+		"Write a checkpoint to the given path with every snapshot and at the end of the run.")
+
+	flag.StringVar(
+		&params.Resume,
+		"resume",
+		"",
+		"Resume the run from the checkpoint at the given path.")
+
+	flag.StringVar(
+		&params.DeltaLog,
+		"delta-log",
+		"",
+		"Write a delta log of the run to the given path, which can be played back with 'go run ./replay'.")
+
+	flag.BoolVar(
+		&params.Compress,
+		"compress",
+		false,
+		"Gzip pgm images, checkpoints and delta logs.")
+
+	flag.DurationVar(
+		&params.AliveInterval,
+		"alive-interval",
+		2*time.Second,
+		"Specify the interval between alive cell count reports. Defaults to 2s.")
+
+	flag.IntVar(
+		&params.AliveEveryTurns,
+		"alive-every",
+		0,
+		"Report the alive cell count every n turns instead of at an interval. Defaults to 0, which reports at an interval.")
+
+	flag.IntVar(
+		&params.StatsEvery,
+		"stats-every",
+		0,
+		"Send a PopulationStats event every n turns. Defaults to 0, which disables it.")
+
+	flag.IntVar(
+		&params.StatsBands,
+		"stats-bands",
+		8,
+		"Specify the number of horizontal bands in PopulationStats. Defaults to 8.")
+
+	statsCSV := flag.String(
+		"stats-csv",
+		"",
+		"Write the alive cells, births and deaths to the given csv file every -stats-every turns (every turn by default).")
+
+	eventsOut := flag.String(
+		"events-out",
+		"",
+		"Write every event to the given file as newline delimited JSON.")
+
+	batchFlips := flag.Bool(
+		"batch-flips",
+		false,
+		"Give the visualisation and -events-out the cells flipped in a turn as a single CellsFlipped event instead of one CellFlipped event per cell.")
+
+	flag.IntVar(
+		&params.TrackEvery,
+		"track-every",
+		0,
+		"Follow the spaceships in the world every n turns, sending ObjectsMoved events. Defaults to 0, which disables it.")
+
+	tracks := flag.String(
+		"tracks",
+		"",
+		"Write a summary of the spaceships followed during the run to the given file, every -track-every turns (every turn by default).")
+
+	flag.BoolVar(
+		&params.Activity,
+		"activity",
+		false,
+		"Save images of how often and how recently every cell changed at the end of the run.")
+
+	flag.StringVar(
+		&params.ActivityGradient,
+		"activity-gradient",
+		"grey",
+		"Specify the colours of the activity images: grey or heat. Defaults to grey.")
+
+	flag.BoolVar(
+		&params.Census,
+		"census",
+		false,
+		"Take a census of the objects in the world at the end of the run. Press c to take one at any time.")
+
 	noVis := flag.Bool(
 		"noVis",
 		false,
 		"Disables the SDL window, so there is no visualisation during the tests.")
 
+	term := flag.Bool(
+		"term",
+		false,
+		"Draw the board in the terminal instead of the SDL window, for machines without a display.")
+
 	flag.Parse()
 
+	if *statsCSV != "" && params.StatsEvery <= 0 {
+		params.StatsEvery = 1
+	}
+	if *tracks != "" && params.TrackEvery <= 0 {
+		params.TrackEvery = 1
+	}
+	// 引擎总是发送批量的翻转事件，由事件中心为每个订阅者转换
+	//the engine always sends batched flips, and the hub converts them for every subscriber
+	params.FlipDelivery = gol.BatchedFlips
+	flips := gol.PerCellFlips
+	if *batchFlips {
+		flips = gol.BatchedFlips
+	}
+
+	if *random != "" {
+		soup, err := parseSoup(*random)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		params.Soup.Density = soup.Density
+		params.Soup.Seed = soup.Seed
+		fmt.Println("Seed:", params.Soup.Seed)
+	}
+
+	var pattern patterns.Pattern
+	if *patternName != "" {
+		var err error
+		pattern, err = patterns.Load(*patternName)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		params.Pattern = &pattern
+	}
+	if *measure {
+		if params.Pattern == nil {
+			fmt.Println("-lifespan needs a -pattern")
+			os.Exit(1)
+		}
+		fmt.Println(lifespan.Measure(params, pattern))
+		return
+	}
+
+	palette, err := util.ParsePalette(*paletteName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	params.Palette = palette
+	// png图像只根据世界绘制，无法按年龄着色
+	//png images are drawn from the world alone, so they cannot colour cells by age
+	if params.ImageFormat == "png" && palette.Aged() {
+		fmt.Printf("-palette %v colours cells by age, which png output cannot show. Use it with the window or -gif instead.\n", *paletteName)
+		os.Exit(1)
+	}
+	if _, ok := util.Gradients[params.ActivityGradient]; !ok {
+		fmt.Println("Unknown gradient:", params.ActivityGradient)
+		os.Exit(1)
+	}
+	gifOptions.Scale = params.ImageScale
+	gifOptions.Palette = palette
+
+	if params.Resume != "" {
+		// 恢复运行时使用检查点中的世界大小，未指定回合数时也使用检查点中的回合数
+		//when resuming, use the world size from the checkpoint, and its number of turns unless -turns is given
+		saved, turn, err := gol.CheckpointParams(params.Resume)
+		util.Check(err)
+		params.ImageWidth = saved.ImageWidth
+		params.ImageHeight = saved.ImageHeight
+		turnsSet := false
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "turns" {
+				turnsSet = true
+			}
+		})
+		if !turnsSet {
+			params.Turns = saved.Turns
+		}
+		fmt.Println("Resuming from turn", turn)
+	}
+
 	fmt.Println("Threads:", params.Threads)
 	fmt.Println("Width:", params.ImageWidth)
 	fmt.Println("Height:", params.ImageHeight)
 
 	keyPresses := make(chan rune, 10)
 	events := make(chan gol.Event, 1000)
+	// 暂停时在窗口中点击细胞产生的编辑
+	//the edits made by clicking on cells in the window while paused
+	edits := make(chan gol.Edit, 100)
+	params.PausedEdits = true
+
+	go gol.RunControlled(params, events, keyPresses, edits)
+
+	// 事件中心把同一次运行的事件分发给可视化和录制器
+	//the hub fans the events of the run out to the visualisation and the recorders
+	hub := gol.NewHub(events)
+	visEvents := hub.Subscribe(nil, flips, 0, gol.Unbounded)
+	// 等待所有写文件的订阅者完成后再退出
+	//wait for every subscriber writing files to finish before exiting
+	var subscribers sync.WaitGroup
+	if *gifPath != "" {
+		subscribers.Add(1)
+		recorder := export.NewGIFRecorder(params, gifOptions)
+		recorded := hub.Subscribe(gol.EventTypes(gol.CellFlipped{}, gol.CellsFlipped{}, gol.TurnComplete{}), gol.BatchedFlips, 0, gol.Unbounded)
+		go func() {
+			err := export.Record(recorder, *gifPath, recorded)
+			// 帧数达到上限时仍然保存了gif，只提示被省略的回合
+			//the gif is still saved when it reaches the frame limit, so only report the turns left out
+			if limit, ok := err.(export.FrameLimitError); ok {
+				fmt.Println(limit)
+			} else {
+				util.Check(err)
+			}
+			fmt.Println("File", *gifPath, "output done!")
+			subscribers.Done()
+		}()
+	}
+	if *eventsOut != "" {
+		file, err := os.Create(*eventsOut)
+		util.Check(err)
+		subscribers.Add(1)
+		all := hub.Subscribe(nil, flips, 0, gol.Unbounded)
+		go func() {
+			err := gol.WriteEvents(all, file)
+			util.Check(err)
+			err = file.Close()
+			util.Check(err)
+			fmt.Println("File", *eventsOut, "output done!")
+			subscribers.Done()
+		}()
+	}
+	if *statsCSV != "" {
+		file, err := os.Create(*statsCSV)
+		util.Check(err)
+		subscribers.Add(1)
+		stats := hub.Subscribe(gol.EventTypes(gol.PopulationStats{}), gol.NoFlips, 0, gol.Unbounded)
+		go func() {
+			err := gol.WriteStatsCSV(stats, file)
+			util.Check(err)
+			err = file.Close()
+			util.Check(err)
+			fmt.Println("File", *statsCSV, "output done!")
+			subscribers.Done()
+		}()
+	}
+	if *tracks != "" {
+		file, err := os.Create(*tracks)
+		util.Check(err)
+		subscribers.Add(1)
+		moved := hub.Subscribe(gol.EventTypes(gol.ObjectsMoved{}), gol.NoFlips, 0, gol.Unbounded)
+		go func() {
+			err := gol.WriteTrackSummary(moved, file)
+			util.Check(err)
+			err = file.Close()
+			util.Check(err)
+			fmt.Println("File", *tracks, "output done!")
+			subscribers.Done()
+		}()
+	}
+	go hub.Run()
 
-	go gol.Run(params, events, keyPresses)
-	if !(*noVis) {
-		sdl.Run(params, events, keyPresses)
+	if *term {
+		terminal.Run(params, visEvents, keyPresses)
+	} else if !(*noVis) {
+		sdl.Run(params, visEvents, keyPresses, edits)
 	} else {
 		complete := false
 		for !complete {
-			event := <-events
+			event := <-visEvents
 			switch event.(type) {
 			case gol.FinalTurnComplete:
 				complete = true
 			}
 		}
 	}
+
+	subscribers.Wait()
+}
+
+// parseSoup 解析 -random 的 density,seed 参数，未指定种子时根据当前时间选择一个
+// parses the density,seed value of -random, picking a seed from the current time when it is left out
+func parseSoup(value string) (gol.Soup, error) {
+	var soup gol.Soup
+	parts := strings.Split(value, ",")
+	if len(parts) > 2 {
+		return soup, fmt.Errorf("-random should be density,seed, got %v", value)
+	}
+	density, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || density <= 0 || density > 1 {
+		return soup, fmt.Errorf("-random density should be between 0 and 1, got %v", parts[0])
+	}
+	soup.Density = density
+	soup.Seed = time.Now().UnixNano()
+	if len(parts) == 2 {
+		soup.Seed, err = strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			return soup, fmt.Errorf("-random seed should be an integer, got %v", parts[1])
+		}
+	}
+	return soup, nil
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/export"
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestPalette tests parsing palettes and colouring cells by age.
+func TestPalette(t *testing.T) {
+	if palette, err := util.ParsePalette("fire"); err != nil || palette != util.Palettes["fire"] {
+		t.Errorf("Expected the fire palette, got %v, %v", palette, err)
+	}
+	custom, err := util.ParsePalette("#000000,#39ff14")
+	if err != nil || custom.Aged() || custom.Alive != (color.RGBA{0x39, 0xFF, 0x14, 0xFF}) {
+		t.Errorf("Unexpected custom palette %v, %v", custom, err)
+	}
+	for _, bad := range []string{"neon", "#000000", "#000000,#FFFFFG", "#000,#FFF"} {
+		if _, err := util.ParsePalette(bad); err == nil {
+			t.Errorf("Expected an error for palette %q", bad)
+		}
+	}
+
+	palette := util.Palettes["fire"]
+	colours := palette.Colours()
+	colour := func(ages *util.CellAges) color.Color {
+		return colours[palette.Index(ages.State(0, 0))]
+	}
+	ages := util.NewCellAges(2, 2)
+	if colour(ages) != palette.Dead {
+		t.Errorf("Expected a cell that was never alive to be dead")
+	}
+	ages.Flip(0, 0)
+	ages.CompleteTurn()
+	if colour(ages) != palette.Newborn {
+		t.Errorf("Expected a newborn cell, got %v", colour(ages))
+	}
+	ages.CompleteTurn()
+	if colour(ages) != palette.Young {
+		t.Errorf("Expected a young cell, got %v", colour(ages))
+	}
+	for i := 0; i < util.OldAge; i++ {
+		ages.CompleteTurn()
+	}
+	if colour(ages) != palette.Old {
+		t.Errorf("Expected an old cell, got %v", colour(ages))
+	}
+	ages.Flip(0, 0)
+	ages.CompleteTurn()
+	if colour(ages) != palette.Trail {
+		t.Errorf("Expected the trail of a dead cell, got %v", colour(ages))
+	}
+	for i := 0; i < util.TrailTurns; i++ {
+		ages.CompleteTurn()
+	}
+	if colour(ages) != palette.Dead {
+		t.Errorf("Expected the trail to fade, got %v", colour(ages))
+	}
+
+	// 按年龄着色的gif中，闪烁器中间的细胞比两端的细胞老
+	//in a gif coloured by age, the middle cell of a blinker is older than its ends
+	p := gol.Params{ImageWidth: 5, ImageHeight: 5}
+	recorder := export.NewGIFRecorder(p, export.GIFOptions{Palette: palette})
+	recorder.Handle(gol.CellsFlipped{Cells: pictureCells([]string{"OOO"}, 1, 2)})
+	for turn := 1; turn <= 4; turn++ {
+		recorder.Handle(gol.CellsFlipped{CompletedTurns: turn - 1, Cells: []util.Cell{{X: 1, Y: 2}, {X: 3, Y: 2}, {X: 2, Y: 1}, {X: 2, Y: 3}}})
+		recorder.Handle(gol.TurnComplete{CompletedTurns: turn})
+	}
+	path := filepath.Join(t.TempDir(), "blinker.gif")
+	err = recorder.Save(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	anim, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := anim.Image[len(anim.Image)-1]
+	middle, end, trail := last.At(2, 2), last.At(1, 2), last.At(2, 1)
+	if middle != colours[palette.Index(true, 4)] || end != palette.Newborn || trail != palette.Trail {
+		t.Errorf("Unexpected colours %v, %v and %v in the last frame", middle, end, trail)
+	}
+}
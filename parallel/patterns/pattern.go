@@ -0,0 +1,195 @@
+// Package patterns parses, transforms and provides Game of Life patterns that can be stamped into a world.
+package patterns
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Pattern is a rectangle of cells.
+type Pattern struct {
+	Name          string
+	Width, Height int
+	// Cells are the alive cells, with 0 <= X < Width and 0 <= Y < Height.
+	Cells []util.Cell
+}
+
+// Transform rotates and reflects a pattern.
+type Transform struct {
+	// Rotate is the number of quarter turns clockwise.
+	Rotate int
+	// Reflect mirrors the pattern left to right before rotating it.
+	Reflect bool
+}
+
+// Transform returns the pattern rotated and reflected by t.
+func (p Pattern) Transform(t Transform) Pattern {
+	result := Pattern{Name: p.Name, Width: p.Width, Height: p.Height, Cells: make([]util.Cell, len(p.Cells))}
+	copy(result.Cells, p.Cells)
+	if t.Reflect {
+		for i, cell := range result.Cells {
+			result.Cells[i].X = result.Width - 1 - cell.X
+		}
+	}
+	for turn := 0; turn < (t.Rotate%4+4)%4; turn++ {
+		for i, cell := range result.Cells {
+			result.Cells[i] = util.Cell{X: result.Height - 1 - cell.Y, Y: cell.X}
+		}
+		result.Width, result.Height = result.Height, result.Width
+	}
+	return result
+}
+
+// ParseRLE reads a pattern in the run length encoded format used by most Life software.
+// Only patterns for B3/S23 are accepted.
+func ParseRLE(r io.Reader) (Pattern, error) {
+	var p Pattern
+	scanner := bufio.NewScanner(r)
+	header := false
+	x, y := 0, 0
+	count := ""
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if strings.HasPrefix(line, "#N") {
+				p.Name = strings.TrimSpace(line[2:])
+			}
+			continue
+		}
+		if !header {
+			header = true
+			err := parseHeader(line, &p)
+			if err != nil {
+				return p, err
+			}
+			continue
+		}
+		for _, c := range line {
+			switch {
+			case c >= '0' && c <= '9':
+				count += string(c)
+				continue
+			case unicode.IsSpace(c):
+				continue
+			}
+			n := 1
+			if count != "" {
+				n, _ = strconv.Atoi(count)
+				count = ""
+			}
+			switch c {
+			case 'b', '.':
+				x += n
+			case '$':
+				x = 0
+				y += n
+			case '!':
+				return p, p.check()
+			default:
+				if !unicode.IsLetter(c) {
+					return p, fmt.Errorf("unexpected %q in rle pattern", c)
+				}
+				for i := 0; i < n; i++ {
+					p.Cells = append(p.Cells, util.Cell{X: x, Y: y})
+					x++
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return p, err
+	}
+	if !header {
+		return p, fmt.Errorf("rle pattern has no header")
+	}
+	return p, p.check()
+}
+
+// parseHeader reads the size and rule from a header like "x = 3, y = 3, rule = B3/S23".
+func parseHeader(line string, p *Pattern) error {
+	for _, field := range strings.Split(line, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("malformed rle header %q", line)
+		}
+		value := strings.TrimSpace(parts[1])
+		var err error
+		switch strings.TrimSpace(parts[0]) {
+		case "x":
+			p.Width, err = strconv.Atoi(value)
+		case "y":
+			p.Height, err = strconv.Atoi(value)
+		case "rule":
+			rule := strings.ToUpper(value)
+			if rule != "B3/S23" && rule != "23/3" && rule != "B3S23" {
+				return fmt.Errorf("unsupported rule %v", value)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("malformed rle header %q", line)
+		}
+	}
+	return nil
+}
+
+// check makes sure every cell is within the size of the pattern.
+func (p Pattern) check() error {
+	for _, cell := range p.Cells {
+		if cell.X >= p.Width || cell.Y >= p.Height {
+			return fmt.Errorf("rle pattern is larger than %vx%v", p.Width, p.Height)
+		}
+	}
+	return nil
+}
+
+// ParseRLEString parses a pattern from an rle string, see ParseRLE.
+func ParseRLEString(rle string) (Pattern, error) {
+	return ParseRLE(strings.NewReader(rle))
+}
+
+// Load reads the rle pattern in the file at path, or the pattern in the Library with that name.
+func Load(path string) (Pattern, error) {
+	if _, ok := Library[path]; ok {
+		return Named(path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return Pattern{}, err
+	}
+	defer file.Close()
+	return ParseRLE(file)
+}
+
+// Named returns the pattern in the Library with the given name.
+func Named(name string) (Pattern, error) {
+	rle, ok := Library[name]
+	if !ok {
+		return Pattern{}, fmt.Errorf("unknown pattern %v", name)
+	}
+	p, err := ParseRLEString(rle)
+	if p.Name == "" {
+		p.Name = name
+	}
+	return p, err
+}
+
+// Names returns the names of the patterns in the Library, in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(Library))
+	for name := range Library {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"image"
+	"sort"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/analysis"
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/patterns"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestPatterns tests the rle parser against the built-in library and the transforms.
+func TestPatterns(t *testing.T) {
+	periods := map[string]int{"block": 1, "blinker": 2, "pulsar": 3, "pentadecathlon": 15}
+	for _, name := range patterns.Names() {
+		pattern, err := patterns.Named(name)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if period, ok := periods[name]; ok {
+			cells := pattern.Cells
+			for i := 0; i < period; i++ {
+				cells = analysis.Step(cells)
+			}
+			if !sameCells(cells, pattern.Cells) {
+				t.Errorf("%v does not have period %v", name, period)
+			}
+		}
+	}
+
+	glider, err := patterns.ParseRLEString("#N Glider\n#C a comment\nx = 3, y = 3, rule = B3/S23\nbo$2b\no$3o!")
+	if err != nil || glider.Name != "Glider" || !sameCells(glider.Cells, pictureCells([]string{".O.", "..O", "OOO"}, 0, 0)) {
+		t.Fatalf("Unexpected glider %v, %v", glider, err)
+	}
+	rotated := glider.Transform(patterns.Transform{Rotate: 1})
+	if !sameCells(rotated.Cells, pictureCells([]string{"O..", "O.O", "OO."}, 0, 0)) {
+		t.Fatalf("Unexpected rotated glider %v", rotated.Cells)
+	}
+	reflected := glider.Transform(patterns.Transform{Reflect: true})
+	if !sameCells(reflected.Cells, pictureCells([]string{".O.", "O..", "OOO"}, 0, 0)) {
+		t.Fatalf("Unexpected reflected glider %v", reflected.Cells)
+	}
+	if _, err := patterns.ParseRLEString("x = 3, y = 1, rule = B36/S23\n3o!"); err == nil {
+		t.Fatal("Expected an error for an unsupported rule")
+	}
+}
+
+// TestEdits tests stamping, clearing and toggling cells before the first turn and while paused.
+func TestEdits(t *testing.T) {
+	p := gol.Params{
+		Turns:       4,
+		Threads:     4,
+		ImageWidth:  16,
+		ImageHeight: 16,
+		OutputDir:   t.TempDir(),
+	}
+	block, _ := patterns.Named("block")
+	glider, _ := patterns.Named("glider")
+	edits := make(chan gol.Edit, 4)
+	edits <- gol.Clear{Rect: image.Rect(0, 0, 16, 16)}
+	edits <- gol.Stamp{Pattern: block, X: 15, Y: 15}
+	edits <- gol.Stamp{Pattern: glider, X: 4, Y: 4, Transform: patterns.Transform{Rotate: 1}}
+	edits <- gol.Toggle{Cells: []util.Cell{{X: 10, Y: 2}}}
+	events := make(chan gol.Event)
+	go gol.RunControlled(p, events, nil, edits)
+
+	expected := []util.Cell{{X: 15, Y: 15}, {X: 0, Y: 15}, {X: 15, Y: 0}, {X: 0, Y: 0}}
+	moved := glider.Transform(patterns.Transform{Rotate: 1}).Cells
+	for i := 0; i < p.Turns; i++ {
+		moved = analysis.Step(moved)
+	}
+	for _, cell := range moved {
+		expected = append(expected, util.Cell{X: cell.X + 4, Y: cell.Y + 4})
+	}
+
+	world := make(map[util.Cell]bool)
+	editsComplete := 0
+	for event := range events {
+		switch e := event.(type) {
+		case gol.CellFlipped:
+			world[e.Cell] = !world[e.Cell]
+		case gol.EditComplete:
+			editsComplete++
+		case gol.TurnComplete:
+			if editsComplete != 4 {
+				t.Fatalf("Expected 4 edits before turn %v, got %v", e.CompletedTurns, editsComplete)
+			}
+		case gol.FinalTurnComplete:
+			if !sameCells(e.Alive, expected) {
+				t.Fatalf("Expected %v alive cells, got %v", expected, e.Alive)
+			}
+			var flipped []util.Cell
+			for cell, alive := range world {
+				if alive {
+					flipped = append(flipped, cell)
+				}
+			}
+			if !sameCells(flipped, expected) {
+				t.Fatalf("The flipped cells do not match the final world")
+			}
+		}
+	}
+}
+
+// TestEditWhilePaused tests that edits are applied while the run is paused.
+func TestEditWhilePaused(t *testing.T) {
+	p := gol.Params{
+		Turns:       100000000,
+		Threads:     4,
+		ImageWidth:  16,
+		ImageHeight: 16,
+		OutputDir:   t.TempDir(),
+	}
+	keyPresses := make(chan rune, 2)
+	edits := make(chan gol.Edit)
+	events := make(chan gol.Event, 1000)
+	go gol.RunControlled(p, events, keyPresses, edits)
+
+	keyPresses <- 'p'
+	paused := false
+	pausedTurn := 0
+	edited := false
+	for event := range events {
+		switch e := event.(type) {
+		case gol.StateChange:
+			if e.NewState == gol.Paused {
+				paused = true
+				pausedTurn = e.CompletedTurns
+				edits <- gol.Toggle{Cells: []util.Cell{{X: 8, Y: 8}}}
+			} else if e.NewState == gol.Executing {
+				paused = false
+			}
+		case gol.EditComplete:
+			if !paused || e.CompletedTurns != pausedTurn {
+				t.Fatalf("Expected the edit while paused at turn %v, got turn %v", pausedTurn, e.CompletedTurns)
+			}
+			edited = true
+			keyPresses <- 'p'
+			keyPresses <- 'q'
+		case gol.TurnComplete:
+			if paused {
+				t.Fatalf("Turn %v completed while paused", e.CompletedTurns)
+			}
+		}
+	}
+	if !edited {
+		t.Fatal("The edit was not applied")
+	}
+}
+
+// TestPausedEdits tests that edits are discarded while running with Params.PausedEdits.
+func TestPausedEdits(t *testing.T) {
+	p := gol.Params{
+		Turns:       10,
+		Threads:     4,
+		ImageWidth:  16,
+		ImageHeight: 16,
+		OutputDir:   t.TempDir(),
+		PausedEdits: true,
+	}
+	edits := make(chan gol.Edit, 1)
+	edits <- gol.Toggle{Cells: []util.Cell{{X: 8, Y: 8}}}
+	events := make(chan gol.Event)
+	go gol.RunControlled(p, events, nil, edits)
+	for event := range events {
+		if _, ok := event.(gol.EditComplete); ok {
+			t.Fatal("Expected the edit to be discarded while running")
+		}
+	}
+	if len(edits) != 0 {
+		t.Errorf("Expected the edit to be taken from the channel")
+	}
+}
+
+// TestEditNetFlips tests that an edit flipping a cell more than once only reports its net flips,
+// with a repeated cell in a Toggle and a Stamp wider than the world wrapping onto itself.
+func TestEditNetFlips(t *testing.T) {
+	p := gol.Params{
+		Turns:       1,
+		Threads:     4,
+		ImageWidth:  16,
+		ImageHeight: 16,
+		OutputDir:   t.TempDir(),
+		StatsEvery:  1,
+	}
+	// (0, 0) is set and then cleared by (16, 0), which wraps onto it, leaving a block at (1, 0)
+	wide := patterns.Pattern{
+		Width:  20,
+		Height: 2,
+		Cells:  []util.Cell{{X: 0, Y: 0}, {X: 17, Y: 0}, {X: 18, Y: 0}, {X: 17, Y: 1}, {X: 18, Y: 1}},
+	}
+	edits := make(chan gol.Edit, 3)
+	edits <- gol.Clear{Rect: image.Rect(0, 0, 16, 16)}
+	edits <- gol.Toggle{Cells: []util.Cell{{X: 10, Y: 10}, {X: 10, Y: 10}, {X: 12, Y: 12}, {X: 12, Y: 12}, {X: 12, Y: 12}}}
+	edits <- gol.Stamp{Pattern: wide, X: 4, Y: 4}
+	events := make(chan gol.Event)
+	go gol.RunControlled(p, events, nil, edits)
+
+	expected := []util.Cell{{X: 5, Y: 4}, {X: 6, Y: 4}, {X: 5, Y: 5}, {X: 6, Y: 5}}
+	world := make(map[util.Cell]bool)
+	// edited 记录Clear之后当前编辑翻转的细胞，每个细胞只能出现一次
+	//edited records the cells flipped by the current edit after the Clear, each of which may only appear once
+	edited := make(map[util.Cell]bool)
+	editsComplete := 0
+	for event := range events {
+		switch e := event.(type) {
+		case gol.CellFlipped:
+			if editsComplete == 1 || editsComplete == 2 {
+				if edited[e.Cell] {
+					t.Fatalf("Cell %v flipped more than once by edit %v", e.Cell, editsComplete+1)
+				}
+				edited[e.Cell] = true
+			}
+			world[e.Cell] = !world[e.Cell]
+		case gol.EditComplete:
+			editsComplete++
+			edited = make(map[util.Cell]bool)
+			if editsComplete == 3 {
+				var alive []util.Cell
+				for cell, isAlive := range world {
+					if isAlive {
+						alive = append(alive, cell)
+					}
+				}
+				if !sameCells(alive, append(expected, util.Cell{X: 12, Y: 12})) {
+					t.Fatalf("Unexpected world after the edits %v", alive)
+				}
+			}
+		case gol.PopulationStats:
+			if e.Alive != len(expected) {
+				t.Errorf("Expected %v alive cells in the stats of turn %v, got %v", len(expected), e.CompletedTurns, e.Alive)
+			}
+			if e.Bounds != image.Rect(5, 4, 7, 6) {
+				t.Errorf("Expected the bounds of the block, got %v", e.Bounds)
+			}
+		case gol.FinalTurnComplete:
+			if !sameCells(e.Alive, expected) {
+				t.Fatalf("Expected %v alive cells, got %v", expected, e.Alive)
+			}
+		}
+	}
+}
+
+// sameCells reports whether a and b contain the same cells, in any order.
+func sameCells(a, b []util.Cell) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sorted := func(cells []util.Cell) []util.Cell {
+		c := append([]util.Cell(nil), cells...)
+		sort.Slice(c, func(i, j int) bool {
+			if c[i].Y != c[j].Y {
+				return c[i].Y < c[j].Y
+			}
+			return c[i].X < c[j].X
+		})
+		return c
+	}
+	a, b = sorted(a), sorted(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"uk.ac.bris.cs/gameoflife/analysis"
+	"uk.ac.bris.cs/gameoflife/patterns"
+)
+
+// main finds the period of a pattern on an unbounded plane, for example 'go run ./period glider.rle'.
+// Built-in patterns can be given by name, for example 'go run ./period lwss'.
+func main() {
+	generations := flag.Int("generations", 1000, "Specify the most generations to evolve the pattern for. Defaults to 1000.")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("Usage: period [-generations n] <rle file or pattern name>")
+		os.Exit(2)
+	}
+
+	pattern, err := patterns.Load(flag.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	name := pattern.Name
+	if name == "" {
+		name = flag.Arg(0)
+	}
+	fmt.Printf("%v: %v\n", name, analysis.FindPeriod(pattern.Cells, *generations))
+}
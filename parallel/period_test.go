@@ -0,0 +1,57 @@
+package main
+
+import (
+	"image"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/analysis"
+	"uk.ac.bris.cs/gameoflife/patterns"
+)
+
+// TestPeriod tests the period finder on patterns from the library and a puffer.
+func TestPeriod(t *testing.T) {
+	tests := []struct {
+		name  string
+		rle   string
+		want  analysis.Evolution
+		speed string
+	}{
+		{"block", "", analysis.Evolution{Kind: analysis.StillLife, Period: 1, Population: 4}, "0"},
+		{"pulsar", "", analysis.Evolution{Kind: analysis.Oscillator, Period: 3, Population: 48}, "0"},
+		{"glider", "", analysis.Evolution{Kind: analysis.Spaceship, Period: 4, Displacement: image.Pt(1, 1), Population: 5}, "c/4"},
+		{"lwss", "", analysis.Evolution{Kind: analysis.Spaceship, Period: 4, Displacement: image.Pt(-2, 0), Population: 9}, "c/2"},
+		{"diehard", "", analysis.Evolution{Died: true, Generation: 130}, "0"},
+		// L形的三个细胞在一代后变成一个方块
+		//an L of three cells becomes a block after one generation
+		{"tromino", "x = 2, y = 2\n2o$o!", analysis.Evolution{Kind: analysis.StillLife, Generation: 1, Period: 1, Population: 4}, "0"},
+		{"blinker-puffer", "x = 9, y = 18\n3bo5b$bo3bo3b$o8b$o4bo3b$5o4b$9b$9b$9b$b2o6b$2ob3o3b$b4o4b$2b2o5b$9b$5b2o2b$3bo4bo$2bo6b$2bo5bo$2b6o!",
+			analysis.Evolution{Kind: analysis.Puffer, Period: 8, Displacement: image.Pt(-4, 0)}, "c/2"},
+		{"gosper-glider-gun", "", analysis.Evolution{}, "0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var pattern patterns.Pattern
+			var err error
+			if test.rle != "" {
+				pattern, err = patterns.ParseRLEString(test.rle)
+			} else {
+				pattern, err = patterns.Named(test.name)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := analysis.FindPeriod(pattern.Cells, 300)
+			if test.want.Kind == analysis.Unknown && !test.want.Died {
+				// 没有找到周期时只检查类型
+				//only the kind is checked when no period is found
+				got.Population = 0
+			}
+			if got != test.want {
+				t.Errorf("Expected %+v, got %+v", test.want, got)
+			}
+			if got.Speed() != test.speed {
+				t.Errorf("Expected speed %v, got %v", test.speed, got.Speed())
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
 	"testing"
 	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
 )
 
 // Pgm tests 16x16, 64x64 and 512x512 image output files on 0, 1 and 100 turns using 1-16 worker threads.
@@ -40,3 +44,156 @@ func TestPgm(t *testing.T) {
 		}
 	}
 }
+
+// TestPgmTemplate tests that output images follow the configured directory and filename template.
+func TestPgmTemplate(t *testing.T) {
+	p := gol.Params{
+		Turns:          1,
+		Threads:        4,
+		ImageWidth:     16,
+		ImageHeight:    16,
+		OutputDir:      t.TempDir(),
+		OutputTemplate: "{run}/{rule}-{width}-{height}-{turn}",
+		RunID:          "template-test",
+	}
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	var filename string
+	for event := range events {
+		switch e := event.(type) {
+		case gol.ImageOutputComplete:
+			filename = e.Filename
+		}
+	}
+	if filename != "template-test/B3S23-16-16-1" {
+		t.Fatalf("expected filename template-test/B3S23-16-16-1, got %v", filename)
+	}
+	expectedAlive := readAliveCells("check/images/16x16x1.pgm", 16, 16)
+	cellsFromImage := readAliveCells(filepath.Join(p.OutputDir, filename+".pgm"), 16, 16)
+	assertEqualBoard(t, cellsFromImage, expectedAlive, p)
+}
+
+// BenchmarkPgm measures loading and saving images of each size without processing any turns,
+// so the time is dominated by the transfer between the distributor and io and by the pgm writer.
+func BenchmarkPgm(b *testing.B) {
+	os.Stdout = nil // Disable all program output apart from benchmark results
+	for _, size := range []int{64, 128, 256, 512} {
+		p := gol.Params{
+			Turns:       0,
+			Threads:     1,
+			ImageWidth:  size,
+			ImageHeight: size,
+			OutputDir:   b.TempDir(),
+		}
+		b.Run(fmt.Sprintf("%dx%d", p.ImageWidth, p.ImageHeight), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				events := make(chan gol.Event)
+				go gol.Run(p, events, nil)
+				for range events {
+				}
+			}
+		})
+	}
+}
+
+// TestPng tests that png output contains the same board as the expected pgm image.
+func TestPng(t *testing.T) {
+	p := gol.Params{
+		Turns:       100,
+		Threads:     4,
+		ImageWidth:  64,
+		ImageHeight: 64,
+		OutputDir:   t.TempDir(),
+		ImageFormat: "png",
+		ImageScale:  2,
+	}
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	for range events {
+	}
+	file, err := os.Open(filepath.Join(p.OutputDir, "64x64x100.png"))
+	util.Check(err)
+	defer file.Close()
+	img, err := png.Decode(file)
+	util.Check(err)
+
+	var cellsFromImage []util.Cell
+	for y := 0; y < p.ImageHeight; y++ {
+		for x := 0; x < p.ImageWidth; x++ {
+			if r, _, _, _ := img.At(x*p.ImageScale, y*p.ImageScale).RGBA(); r != 0 {
+				cellsFromImage = append(cellsFromImage, util.Cell{X: x, Y: y})
+			}
+		}
+	}
+	expectedAlive := readAliveCells("check/images/64x64x100.pgm", 64, 64)
+	assertEqualBoard(t, cellsFromImage, expectedAlive, p)
+}
+
+// TestPgmSnapshots tests that periodic snapshots are written and only the most recent ones are kept.
+func TestPgmSnapshots(t *testing.T) {
+	p := gol.Params{
+		Turns:         100,
+		Threads:       4,
+		ImageWidth:    16,
+		ImageHeight:   16,
+		OutputDir:     t.TempDir(),
+		SnapshotEvery: 10,
+		SnapshotKeep:  3,
+	}
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	snapshots := 0
+	for event := range events {
+		switch e := event.(type) {
+		case gol.ImageOutputComplete:
+			if e.Filename != fmt.Sprintf("16x16x%d", p.Turns) {
+				snapshots++
+			}
+		}
+	}
+	if snapshots != p.Turns/p.SnapshotEvery {
+		t.Fatalf("expected %v snapshot ImageOutputComplete events, got %v", p.Turns/p.SnapshotEvery, snapshots)
+	}
+	files, err := filepath.Glob(filepath.Join(p.OutputDir, "snapshot-*.pgm"))
+	util.Check(err)
+	if len(files) != p.SnapshotKeep {
+		t.Fatalf("expected %v snapshots to be kept, found %v", p.SnapshotKeep, files)
+	}
+	expectedAlive := readAliveCells("check/images/16x16x100.pgm", 16, 16)
+	cellsFromImage := readAliveCells(filepath.Join(p.OutputDir, "snapshot-16x16x100.pgm"), 16, 16)
+	assertEqualBoard(t, cellsFromImage, expectedAlive, p)
+}
+
+// TestPgmCompressed tests that compressed pgm output can be read back as compressed input.
+func TestPgmCompressed(t *testing.T) {
+	p := gol.Params{
+		Turns:       100,
+		Threads:     4,
+		ImageWidth:  64,
+		ImageHeight: 64,
+		OutputDir:   t.TempDir(),
+		Compress:    true,
+	}
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	for range events {
+	}
+
+	// 把压缩后的输出作为下一次运行的输入
+	//use the compressed output as the input of the next run
+	next := p
+	next.Turns = 0
+	next.InputDir = p.OutputDir
+	next.OutputDir = t.TempDir()
+	next.Compress = false
+	err := os.Rename(filepath.Join(p.OutputDir, "64x64x100.pgm.gz"), filepath.Join(next.InputDir, "64x64.pgm.gz"))
+	util.Check(err)
+	events = make(chan gol.Event)
+	go gol.Run(next, events, nil)
+	for range events {
+	}
+
+	expectedAlive := readAliveCells("check/images/64x64x100.pgm", 64, 64)
+	cellsFromImage := readAliveCells(filepath.Join(next.OutputDir, "64x64x0.pgm"), 64, 64)
+	assertEqualBoard(t, cellsFromImage, expectedAlive, p)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/sdl"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// main replays a delta log written with -delta-log, for example 'go run ./replay run.log'
+func main() {
+	runtime.LockOSThread()
+	delay := flag.Duration("delay", 0, "Specify the delay between replayed turns, e.g. 20ms. Defaults to 0.")
+	noVis := flag.Bool("noVis", false, "Disables the SDL window and only prints the final number of alive cells.")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("Usage: replay [-delay d] [-noVis] <delta log>")
+		os.Exit(2)
+	}
+
+	log, err := gol.OpenDeltaLog(flag.Arg(0))
+	util.Check(err)
+	defer log.Close()
+	log.FlipDelivery = gol.BatchedFlips
+	p := log.Params()
+	fmt.Println("Width:", p.ImageWidth)
+	fmt.Println("Height:", p.ImageHeight)
+	fmt.Println("Starting turn:", log.StartTurn)
+
+	keyPresses := make(chan rune, 10)
+	events := make(chan gol.Event, 1000)
+	go func() {
+		err := log.Replay(events, keyPresses, *delay)
+		util.Check(err)
+	}()
+
+	if !(*noVis) {
+		sdl.Run(p, events, keyPresses, nil)
+	} else {
+		for event := range events {
+			switch e := event.(type) {
+			case gol.FinalTurnComplete:
+				fmt.Printf("Completed Turns %-8v%v alive cells\n", e.CompletedTurns, len(e.Alive))
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestReplay tests that replaying a delta log produces the same turns and final board as the logged run.
+func TestReplay(t *testing.T) {
+	for _, size := range []int{16, 64} {
+		p := gol.Params{
+			Turns:       100,
+			Threads:     4,
+			ImageWidth:  size,
+			ImageHeight: size,
+			OutputDir:   t.TempDir(),
+		}
+		p.DeltaLog = filepath.Join(p.OutputDir, "run.log")
+		// 64x64使用压缩的增量日志 64x64 uses a compressed delta log
+		p.Compress = size == 64
+		t.Run(fmt.Sprintf("%dx%d", size, size), func(t *testing.T) {
+			events := make(chan gol.Event)
+			go gol.Run(p, events, nil)
+			for range events {
+			}
+
+			log, err := gol.OpenDeltaLog(p.DeltaLog)
+			util.Check(err)
+			defer log.Close()
+			if log.Width != size || log.Height != size || log.StartTurn != 0 {
+				t.Fatalf("delta log header is %vx%v from turn %v", log.Width, log.Height, log.StartTurn)
+			}
+			events = make(chan gol.Event)
+			go func() {
+				err := log.Replay(events, nil, 0)
+				util.Check(err)
+			}()
+			turns := 0
+			var cells []util.Cell
+			for event := range events {
+				switch e := event.(type) {
+				case gol.TurnComplete:
+					turns++
+				case gol.FinalTurnComplete:
+					cells = e.Alive
+				}
+			}
+			if turns != p.Turns {
+				t.Errorf("expected %v replayed turns, got %v", p.Turns, turns)
+			}
+			expectedAlive := readAliveCells(
+				fmt.Sprintf("check/images/%vx%vx100.pgm", size, size),
+				size,
+				size,
+			)
+			assertEqualBoard(t, cells, expectedAlive, p)
+		})
+	}
+}
+
+// TestReplayEdits tests that edits are replayed, including an edit made after the last turn.
+func TestReplayEdits(t *testing.T) {
+	p := gol.Params{
+		Turns:           10,
+		Threads:         4,
+		ImageWidth:      16,
+		ImageHeight:     16,
+		OutputDir:       t.TempDir(),
+		AliveEveryTurns: 10,
+	}
+	p.DeltaLog = filepath.Join(p.OutputDir, "run.log")
+	edits := make(chan gol.Edit, 2)
+	edits <- gol.Toggle{Cells: []util.Cell{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0}}}
+	events := make(chan gol.Event)
+	go gol.RunControlled(p, events, nil, edits)
+
+	var final []util.Cell
+	for event := range events {
+		switch e := event.(type) {
+		case gol.TurnComplete:
+			// 最后一个回合完成后，distributor在发送AliveCellsCount时等待，所以编辑在最后一个回合之后应用
+			//after the last turn the distributor waits to send AliveCellsCount, so the edit is applied after the last turn
+			if e.CompletedTurns == p.Turns {
+				edits <- gol.Toggle{Cells: []util.Cell{{X: 8, Y: 15}}}
+			}
+		case gol.FinalTurnComplete:
+			final = e.Alive
+		}
+	}
+
+	log, err := gol.OpenDeltaLog(p.DeltaLog)
+	util.Check(err)
+	defer log.Close()
+	events = make(chan gol.Event)
+	go func() {
+		err := log.Replay(events, nil, 0)
+		util.Check(err)
+	}()
+	turns := 0
+	var editTurns []int
+	var replayed []util.Cell
+	for event := range events {
+		switch e := event.(type) {
+		case gol.TurnComplete:
+			turns++
+		case gol.EditComplete:
+			editTurns = append(editTurns, e.CompletedTurns)
+		case gol.FinalTurnComplete:
+			replayed = e.Alive
+		}
+	}
+	if turns != p.Turns || len(editTurns) != 2 || editTurns[0] != 0 || editTurns[1] != p.Turns {
+		t.Errorf("Expected %v turns with edits at turns 0 and %v, got %v turns with edits at %v", p.Turns, p.Turns, turns, editTurns)
+	}
+	if !sameCells(replayed, final) {
+		t.Errorf("Expected the replay to end with %v, got %v", final, replayed)
+	}
+}
@@ -0,0 +1,82 @@
+package sdl
+
+import (
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Board is the part of a Window an Editor uses: which cell is under the mouse and how the cells are drawn.
+type Board interface {
+	// CellAt returns the cell under pixel x, y of the window, and false when there is no cell under it.
+	CellAt(x, y int32) (util.Cell, bool)
+	// Alive reports whether the cell at x, y is drawn alive.
+	Alive(x, y int) bool
+}
+
+// Editor turns mouse clicks and drags into edits of the world. A click toggles the cell under the mouse,
+// and dragging from it paints the cells passed over with the new state of that cell.
+type Editor struct {
+	edits    chan<- gol.Edit
+	painting bool
+	alive    bool
+	last     util.Cell
+	// painted holds the cells toggled by the current drag, which the window shows only once the edits are applied.
+	painted map[util.Cell]bool
+}
+
+// NewEditor creates an editor sending its edits to edits, which may be nil when the world cannot be edited.
+func NewEditor(edits chan<- gol.Edit) *Editor {
+	return &Editor{edits: edits}
+}
+
+// Start begins painting at pixel x, y of the window.
+func (e *Editor) Start(b Board, x, y int32) {
+	cell, ok := b.CellAt(x, y)
+	if !ok || e.edits == nil {
+		return
+	}
+	e.painting = true
+	e.alive = !b.Alive(cell.X, cell.Y)
+	e.last = cell
+	e.painted = map[util.Cell]bool{}
+	e.paint(b, cell)
+}
+
+// Move paints every cell on the line from the last cell painted to the cell under pixel x, y of the window.
+func (e *Editor) Move(b Board, x, y int32) {
+	cell, ok := b.CellAt(x, y)
+	if !e.painting || !ok || cell == e.last {
+		return
+	}
+	dx, dy := cell.X-e.last.X, cell.Y-e.last.Y
+	steps := maxInt(abs(dx), abs(dy))
+	for i := 1; i <= steps; i++ {
+		e.paint(b, util.Cell{X: e.last.X + dx*i/steps, Y: e.last.Y + dy*i/steps})
+	}
+	e.last = cell
+}
+
+// Stop ends painting, when the mouse button is released or the run is resumed.
+func (e *Editor) Stop() {
+	e.painting = false
+}
+
+func (e *Editor) paint(b Board, cell util.Cell) {
+	if e.painted[cell] || b.Alive(cell.X, cell.Y) == e.alive {
+		return
+	}
+	select {
+	case e.edits <- gol.Toggle{Cells: []util.Cell{cell}}:
+		e.painted[cell] = true
+	default:
+		// Drop the edit when the channel is full, instead of blocking the window.
+		// The cell is not marked as painted, so passing over it again retries.
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -4,10 +4,18 @@ import (
 	"fmt"
 	"github.com/veandco/go-sdl2/sdl"
 	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
 )
 
-func Run(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune) {
+// Run shows the events of a run in a window until the run finishes. While the run is paused, clicking and
+// dragging with the left mouse button toggles cells by sending edits, which may be nil when the world cannot be edited.
+func Run(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.Edit) {
 	w := NewWindow(int32(p.ImageWidth), int32(p.ImageHeight))
+	if p.Palette != (util.Palette{}) {
+		w.SetPalette(p.Palette)
+	}
+	editing := NewEditor(edits)
+	paused := false
 
 sdlLoop:
 	for {
@@ -24,6 +32,38 @@ sdlLoop:
 					keyPresses <- 'q'
 				case sdl.K_k:
 					keyPresses <- 'k'
+				case sdl.K_c:
+					keyPresses <- 'c'
+				default:
+					if navigate(w, e.Keysym.Sym) {
+						w.RenderFrame()
+					}
+				}
+			case *sdl.MouseWheelEvent:
+				x, y, _ := sdl.GetMouseState()
+				w.View().ZoomBy(int(e.Y), int(x), int(y))
+				w.RenderFrame()
+			case *sdl.MouseButtonEvent:
+				if e.Button != sdl.BUTTON_LEFT {
+					break
+				}
+				if e.Type == sdl.MOUSEBUTTONDOWN && paused {
+					editing.Start(w, e.X, e.Y)
+				} else if e.Type == sdl.MOUSEBUTTONUP {
+					editing.Stop()
+				}
+			case *sdl.MouseMotionEvent:
+				if e.State&sdl.ButtonLMask() != 0 {
+					editing.Move(w, e.X, e.Y)
+				}
+				if e.State&(sdl.ButtonRMask()|sdl.ButtonMMask()) != 0 {
+					w.View().Pan(int(e.XRel), int(e.YRel))
+					w.RenderFrame()
+				}
+			case *sdl.WindowEvent:
+				if e.Event == sdl.WINDOWEVENT_SIZE_CHANGED {
+					w.Resize(e.Data1, e.Data2)
+					w.RenderFrame()
 				}
 			}
 		}
@@ -36,8 +76,21 @@ sdlLoop:
 			switch e := event.(type) {
 			case gol.CellFlipped:
 				w.FlipPixel(e.Cell.X, e.Cell.Y)
+			case gol.CellsFlipped:
+				for _, cell := range e.Cells {
+					w.FlipPixel(cell.X, cell.Y)
+				}
 			case gol.TurnComplete:
+				w.CompleteTurn()
+				w.RenderFrame()
+			case gol.EditComplete:
 				w.RenderFrame()
+			case gol.StateChange:
+				paused = e.NewState == gol.Paused
+				if !paused {
+					editing.Stop()
+				}
+				fmt.Printf("Completed Turns %-8v%v\n", event.GetCompletedTurns(), event)
 			case gol.FinalTurnComplete:
 				w.Destroy()
 				break sdlLoop
@@ -52,3 +105,29 @@ sdlLoop:
 	}
 
 }
+
+// navigate zooms and pans the view with the keyboard, reporting whether the key was used.
+// The arrow keys pan, + and - zoom around the centre of the window and f fits the board to the window.
+func navigate(w *Window, key sdl.Keycode) bool {
+	view := w.View()
+	width, height := view.WindowSize()
+	switch key {
+	case sdl.K_LEFT:
+		view.Pan(width/8, 0)
+	case sdl.K_RIGHT:
+		view.Pan(-width/8, 0)
+	case sdl.K_UP:
+		view.Pan(0, height/8)
+	case sdl.K_DOWN:
+		view.Pan(0, -height/8)
+	case sdl.K_EQUALS, sdl.K_PLUS, sdl.K_KP_PLUS:
+		view.ZoomBy(1, width/2, height/2)
+	case sdl.K_MINUS, sdl.K_KP_MINUS:
+		view.ZoomBy(-1, width/2, height/2)
+	case sdl.K_f:
+		view.ToggleFit()
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package sdl
+
+import (
+	"image"
+	"math"
+)
+
+const (
+	// maxWindowSize is the largest width or height of a new window, so large boards do not overflow the screen.
+	maxWindowSize = 1024
+	// minInitialSize is the smallest width or height a small board is zoomed to in a new window.
+	minInitialSize = 512
+	maxZoom        = 64
+)
+
+// View maps the cells of a board to the pixels of a window. Cells are squares of Zoom pixels,
+// or the whole board is scaled to the window in fit mode.
+type View struct {
+	boardWidth, boardHeight   int
+	windowWidth, windowHeight int
+	zoom                      int
+	// origin is the position of the top left corner of the board in the window, in pixels.
+	origin image.Point
+	fit    bool
+}
+
+// NewView creates a view of a board, choosing the size of the window and a zoom that shows small boards larger.
+func NewView(boardWidth, boardHeight int) *View {
+	zoom := 1
+	for zoom < maxZoom && boardWidth*zoom*2 <= minInitialSize && boardHeight*zoom*2 <= minInitialSize {
+		zoom *= 2
+	}
+	v := &View{boardWidth: boardWidth, boardHeight: boardHeight, zoom: zoom}
+	v.Resize(minInt(boardWidth*zoom, maxWindowSize), minInt(boardHeight*zoom, maxWindowSize))
+	v.centre()
+	return v
+}
+
+// WindowSize returns the size of the window in pixels.
+func (v *View) WindowSize() (int, int) {
+	return v.windowWidth, v.windowHeight
+}
+
+// Zoom returns the size of a cell in pixels, which is not a whole number in fit mode.
+func (v *View) Zoom() float64 {
+	if v.fit {
+		return v.fitScale()
+	}
+	return float64(v.zoom)
+}
+
+// Fit reports whether the whole board is scaled to the window.
+func (v *View) Fit() bool {
+	return v.fit
+}
+
+// Resize changes the size of the window, keeping the board in view.
+func (v *View) Resize(windowWidth, windowHeight int) {
+	v.windowWidth, v.windowHeight = maxInt(windowWidth, 1), maxInt(windowHeight, 1)
+	v.clamp()
+}
+
+// ZoomBy multiplies the zoom by 2 for every step, or divides it for negative steps, keeping the cell
+// at pixel x, y of the window in place. It leaves fit mode.
+func (v *View) ZoomBy(steps, x, y int) {
+	cellX, cellY := v.Cell(x, y)
+	if v.fit {
+		v.fit = false
+		v.zoom = maxInt(int(v.fitScale()), 1)
+	}
+	for ; steps > 0 && v.zoom < maxZoom; steps-- {
+		v.zoom *= 2
+	}
+	for ; steps < 0 && v.zoom > 1; steps++ {
+		v.zoom /= 2
+	}
+	v.origin = image.Pt(x-int(cellX*float64(v.zoom)), y-int(cellY*float64(v.zoom)))
+	v.clamp()
+}
+
+// Pan moves the board by dx, dy pixels, stopping at its edges. It does nothing in fit mode.
+func (v *View) Pan(dx, dy int) {
+	if v.fit {
+		return
+	}
+	v.origin = v.origin.Add(image.Pt(dx, dy))
+	v.clamp()
+}
+
+// ToggleFit switches between fit mode and the zoom closest to it.
+func (v *View) ToggleFit() {
+	if v.fit {
+		v.fit = false
+		v.zoom = 1
+		for v.zoom*2 <= int(v.fitScale()) && v.zoom < maxZoom {
+			v.zoom *= 2
+		}
+		v.centre()
+		return
+	}
+	v.fit = true
+}
+
+// Cell returns the board position under pixel x, y of the window, in cells.
+func (v *View) Cell(x, y int) (float64, float64) {
+	if v.fit {
+		_, dst := v.Rects()
+		scale := v.fitScale()
+		return float64(x-dst.Min.X) / scale, float64(y-dst.Min.Y) / scale
+	}
+	return float64(x-v.origin.X) / float64(v.zoom), float64(y-v.origin.Y) / float64(v.zoom)
+}
+
+// Rects returns the cells of the board that are visible and where they are drawn in the window.
+func (v *View) Rects() (src, dst image.Rectangle) {
+	board := image.Rect(0, 0, v.boardWidth, v.boardHeight)
+	if v.fit {
+		scale := v.fitScale()
+		width, height := int(float64(v.boardWidth)*scale), int(float64(v.boardHeight)*scale)
+		min := image.Pt((v.windowWidth-width)/2, (v.windowHeight-height)/2)
+		return board, image.Rectangle{Min: min, Max: min.Add(image.Pt(width, height))}
+	}
+	src = image.Rect(
+		floorDiv(-v.origin.X, v.zoom), floorDiv(-v.origin.Y, v.zoom),
+		ceilDiv(v.windowWidth-v.origin.X, v.zoom), ceilDiv(v.windowHeight-v.origin.Y, v.zoom),
+	).Intersect(board)
+	dst = image.Rectangle{Min: src.Min.Mul(v.zoom), Max: src.Max.Mul(v.zoom)}.Add(v.origin)
+	return src, dst
+}
+
+func (v *View) fitScale() float64 {
+	return math.Min(float64(v.windowWidth)/float64(v.boardWidth), float64(v.windowHeight)/float64(v.boardHeight))
+}
+
+func (v *View) centre() {
+	v.origin = image.Pt((v.windowWidth-v.boardWidth*v.zoom)/2, (v.windowHeight-v.boardHeight*v.zoom)/2)
+	v.clamp()
+}
+
+// clamp centres the board when it is smaller than the window, otherwise it keeps the window covered.
+func (v *View) clamp() {
+	v.origin.X = clampOrigin(v.origin.X, v.boardWidth*v.zoom, v.windowWidth)
+	v.origin.Y = clampOrigin(v.origin.Y, v.boardHeight*v.zoom, v.windowHeight)
+}
+
+func clampOrigin(origin, board, window int) int {
+	if board <= window {
+		return (window - board) / 2
+	}
+	return minInt(maxInt(origin, window-board), 0)
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -ceilDiv(-a, b)
+	}
+	return a / b
+}
+
+func ceilDiv(a, b int) int {
+	if a < 0 {
+		return -floorDiv(-a, b)
+	}
+	return (a + b - 1) / b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -2,6 +2,8 @@ package sdl
 
 import (
 	"fmt"
+	"image"
+	"image/color"
 
 	"github.com/veandco/go-sdl2/sdl"
 	"uk.ac.bris.cs/gameoflife/util"
@@ -13,36 +15,86 @@ type Window struct {
 	renderer      *sdl.Renderer
 	texture       *sdl.Texture
 	pixels        []byte
+	view          *View
+	ages          *util.CellAges
+	palette       util.Palette
+	// colours caches palette.Colours() as RGBA values, so painting a pixel needs no type assertion.
+	colours []color.RGBA
 }
 
 func filterEvent(e sdl.Event, userdata interface{}) bool {
-	return e.GetType() == sdl.KEYDOWN || e.GetType() == sdl.QUIT
+	switch e.GetType() {
+	case sdl.KEYDOWN, sdl.QUIT, sdl.WINDOWEVENT, sdl.MOUSEWHEEL, sdl.MOUSEMOTION, sdl.MOUSEBUTTONDOWN, sdl.MOUSEBUTTONUP:
+		return true
+	}
+	return false
 }
 
 func NewWindow(width, height int32) *Window {
 	err := sdl.Init(sdl.INIT_EVERYTHING)
 	util.Check(err)
-	window, err := sdl.CreateWindow("GOL GUI", sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED, width, height, sdl.WINDOW_SHOWN)
+	view := NewView(int(width), int(height))
+	windowWidth, windowHeight := view.WindowSize()
+	window, err := sdl.CreateWindow("GOL GUI", sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED,
+		int32(windowWidth), int32(windowHeight), sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE)
 	util.Check(err)
 	renderer, err := sdl.CreateRenderer(window, -1, sdl.WINDOW_SHOWN)
 	util.Check(err)
 	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "linear")
-	err = renderer.SetLogicalSize(width, height)
+	err = renderer.SetLogicalSize(int32(windowWidth), int32(windowHeight))
 	util.Check(err)
 	texture, err := renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STATIC, width, height)
 	util.Check(err)
 
 	sdl.SetEventFilterFunc(filterEvent, nil)
-	return &Window{
-		width,
-		height,
-		window,
-		renderer,
-		texture,
-		make([]byte, width*height*4),
+	w := &Window{
+		Width:    width,
+		Height:   height,
+		window:   window,
+		renderer: renderer,
+		texture:  texture,
+		pixels:   make([]byte, width*height*4),
+		view:     view,
+		ages:     util.NewCellAges(int(width), int(height)),
+	}
+	w.SetPalette(util.DefaultPalette)
+	return w
+}
+
+// SetPalette changes the colours of the cells, redrawing every pixel.
+func (w *Window) SetPalette(palette util.Palette) {
+	w.palette = palette
+	w.colours = w.colours[:0]
+	for _, colour := range palette.Colours() {
+		w.colours = append(w.colours, colour.(color.RGBA))
+	}
+	w.paint(image.Rect(0, 0, int(w.Width), int(w.Height)))
+}
+
+// paint sets the pixels of the cells in r from their state and age.
+func (w *Window) paint(r image.Rectangle) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			w.paintPixel(x, y)
+		}
 	}
 }
 
+func (w *Window) paintPixel(x, y int) {
+	colour := w.colours[w.palette.Index(w.ages.State(x, y))]
+	// ARGB8888 pixels are stored as B, G, R, A bytes.
+	i := 4 * (y*int(w.Width) + x)
+	w.pixels[i+0] = colour.B
+	w.pixels[i+1] = colour.G
+	w.pixels[i+2] = colour.R
+	w.pixels[i+3] = colour.A
+}
+
+// CompleteTurn makes every cell a turn older, for palettes that colour cells by age.
+func (w *Window) CompleteTurn() {
+	w.ages.CompleteTurn()
+}
+
 func (w *Window) Destroy() {
 	err := w.texture.Destroy()
 	util.Check(err)
@@ -53,26 +105,70 @@ func (w *Window) Destroy() {
 	sdl.Quit()
 }
 
+// RenderFrame draws the part of the board in the view. Only the visible pixels are uploaded to the texture.
 func (w *Window) RenderFrame() {
-	err := w.texture.Update(nil, w.pixels, int(w.Width*4))
-	util.Check(err)
-	err = w.renderer.Clear()
+	visible, drawn := w.view.Rects()
+	src, dst := rect(visible), rect(drawn)
+	if w.palette.Aged() {
+		// Cells coloured by age change colour even when they do not flip.
+		w.paint(visible)
+	}
+	if !visible.Empty() {
+		start := 4 * (visible.Min.Y*int(w.Width) + visible.Min.X)
+		err := w.texture.Update(&src, w.pixels[start:], int(w.Width*4))
+		util.Check(err)
+	}
+	err := w.renderer.Clear()
 	util.Check(err)
-	err = w.renderer.Copy(w.texture, nil, nil)
+	err = w.renderer.Copy(w.texture, &src, &dst)
 	util.Check(err)
 	w.renderer.Present()
 }
 
+// View returns the view of the board, to zoom and pan it.
+func (w *Window) View() *View {
+	return w.view
+}
+
+// CellAt returns the cell under pixel x, y of the window, reporting false outside the board.
+func (w *Window) CellAt(x, y int32) (util.Cell, bool) {
+	cellX, cellY := w.view.Cell(int(x), int(y))
+	if cellX < 0 || cellY < 0 || cellX >= float64(w.Width) || cellY >= float64(w.Height) {
+		return util.Cell{}, false
+	}
+	return util.Cell{X: int(cellX), Y: int(cellY)}, true
+}
+
+// Alive reports whether the cell at x, y is drawn alive.
+func (w *Window) Alive(x, y int) bool {
+	return w.ages.Alive(x, y)
+}
+
+// Resize updates the view after the window was resized.
+func (w *Window) Resize(width, height int32) {
+	w.view.Resize(int(width), int(height))
+	err := w.renderer.SetLogicalSize(width, height)
+	util.Check(err)
+}
+
+func rect(r image.Rectangle) sdl.Rect {
+	return sdl.Rect{X: int32(r.Min.X), Y: int32(r.Min.Y), W: int32(r.Dx()), H: int32(r.Dy())}
+}
+
 func (w *Window) PollEvent() sdl.Event {
 	return sdl.PollEvent()
 }
 
+// SetPixel makes the cell at x, y alive, painting it with the palette like FlipPixel.
 func (w *Window) SetPixel(x, y int) {
-	width := int(w.Width)
-	w.pixels[4*(y*width+x)+0] = 0xFF
-	w.pixels[4*(y*width+x)+1] = 0xFF
-	w.pixels[4*(y*width+x)+2] = 0xFF
-	w.pixels[4*(y*width+x)+3] = 0xFF
+	if x < 0 || y < 0 || x >= int(w.Width) || y >= int(w.Height) {
+		panic(fmt.Sprintf("Pixel (%d, %d) is outside the bounds of the window.", x, y))
+	}
+
+	if !w.ages.Alive(x, y) {
+		w.ages.Flip(x, y)
+	}
+	w.paintPixel(x, y)
 }
 
 func (w *Window) FlipPixel(x, y int) {
@@ -80,25 +176,23 @@ func (w *Window) FlipPixel(x, y int) {
 		panic(fmt.Sprintf("CellFlipped event at (%d, %d) is outside the bounds of the window.", x, y))
 	}
 
-	width := int(w.Width)
-	w.pixels[4*(y*width+x)+0] = ^w.pixels[4*(y*width+x)+0]
-	w.pixels[4*(y*width+x)+1] = ^w.pixels[4*(y*width+x)+1]
-	w.pixels[4*(y*width+x)+2] = ^w.pixels[4*(y*width+x)+2]
-	w.pixels[4*(y*width+x)+3] = ^w.pixels[4*(y*width+x)+3]
+	w.ages.Flip(x, y)
+	w.paintPixel(x, y)
 }
 
 func (w *Window) CountPixels() int {
 	count := 0
-	for i := 0; i < int(w.Width)*int(w.Height)*4; i += 4 {
-		if w.pixels[i] == 0xFF {
-			count++
+	for y := 0; y < int(w.Height); y++ {
+		for x := 0; x < int(w.Width); x++ {
+			if w.ages.Alive(x, y) {
+				count++
+			}
 		}
 	}
 	return count
 }
 
 func (w *Window) ClearPixels() {
-	for i := range w.pixels {
-		w.pixels[i] = 0
-	}
+	w.ages = util.NewCellAges(int(w.Width), int(w.Height))
+	w.paint(image.Rect(0, 0, int(w.Width), int(w.Height)))
 }
@@ -15,6 +15,10 @@ import (
 var sdlEvents chan gol.Event
 var sdlAlive chan int
 
+// sdlWindow 是测试的SDL窗口，使用-noVis时为nil
+// sdlWindow is the SDL window of the tests, nil with -noVis
+var sdlWindow *sdl.Window
+
 func TestMain(m *testing.M) {
 	runtime.LockOSThread()
 	noVis := flag.Bool("noVis", false,
@@ -36,6 +40,7 @@ func TestMain(m *testing.M) {
 	if !(*noVis) {
 		w = sdl.NewWindow(int32(p.ImageWidth), int32(p.ImageHeight))
 	}
+	sdlWindow = w
 
 	board := make([][]byte, p.ImageHeight)
 	for i := 0; i < p.ImageHeight; i++ {
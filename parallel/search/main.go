@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sort"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// main runs many random soups and reports what they turn into, for example 'go run ./search -soups 1000'.
+// Every finished soup is appended to the state file, so an interrupted search continues where it stopped.
+func main() {
+	var p gol.Params
+	flag.IntVar(&p.ImageWidth, "w", 128, "Specify the width of the world. Defaults to 128.")
+	flag.IntVar(&p.ImageHeight, "h", 128, "Specify the height of the world. Defaults to 128.")
+	flag.IntVar(&p.Turns, "turns", 10000, "Specify the most turns to run each soup for, unless it stabilises earlier. Defaults to 10000.")
+	flag.IntVar(&p.Threads, "t", 1, "Specify the number of worker threads used by each soup. Defaults to 1.")
+	flag.Float64Var(&p.Soup.Density, "density", 0.5, "Specify the density of the soups. Defaults to 0.5.")
+	flag.IntVar(&p.Soup.Size, "soup-size", 16, "Specify the size of the centred n x n soup region. Defaults to 16.")
+	first := flag.Int64("from", 1, "Specify the seed of the first soup. Defaults to 1.")
+	count := flag.Int("soups", 100, "Specify the number of soups to run, with consecutive seeds. Defaults to 100.")
+	concurrent := flag.Int("parallel", runtime.NumCPU(), "Specify the number of soups run at the same time. Defaults to the number of CPUs.")
+	statePath := flag.String("state", "search.state", "Specify the file the results are saved to, to resume the search. Defaults to search.state.")
+	reportPath := flag.String("report", "search-report.txt", "Specify the file the report is written to. Defaults to search-report.txt.")
+	top := flag.Int("top", 10, "Specify the number of longest lived seeds in the report. Defaults to 10.")
+	flag.Parse()
+
+	if p.Soup.Size <= 0 || p.Soup.Size > p.ImageWidth || p.Soup.Size > p.ImageHeight {
+		fmt.Println("-soup-size should be between 1 and the size of the world")
+		os.Exit(2)
+	}
+	if p.Soup.Density <= 0 || p.Soup.Density > 1 {
+		fmt.Println("-density should be between 0 and 1")
+		os.Exit(2)
+	}
+	// 输出的图像没有用处，写入一个临时目录
+	//the output images are not needed, so they are written to a temporary directory
+	outputDir, err := ioutil.TempDir("", "search")
+	util.Check(err)
+	defer os.RemoveAll(outputDir)
+	p.OutputDir = outputDir
+
+	results := readState(*statePath)
+	done := map[int64]bool{}
+	for _, result := range results {
+		done[result.Seed] = true
+	}
+	seeds := make(chan int64, *count)
+	for seed := *first; seed < *first+int64(*count); seed++ {
+		if !done[seed] {
+			seeds <- seed
+		}
+	}
+	close(seeds)
+	fmt.Println("Resuming with", len(results), "soups done,", len(seeds), "to go")
+
+	state, err := os.OpenFile(*statePath, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+	util.Check(err)
+	endState(state)
+	finished := make(chan soupResult)
+	for i := 0; i < *concurrent; i++ {
+		go func() {
+			for seed := range seeds {
+				finished <- runSoup(p, seed)
+			}
+		}()
+	}
+	encoder := json.NewEncoder(state)
+	for remaining := len(seeds); remaining > 0; remaining-- {
+		result := <-finished
+		err = encoder.Encode(result)
+		util.Check(err)
+		results = append(results, result)
+	}
+	err = state.Close()
+	util.Check(err)
+
+	// 报告只包含本次搜索范围内的种子
+	//the report only includes the seeds in the range of this search
+	var searched []soupResult
+	for _, result := range results {
+		if result.Seed >= *first && result.Seed < *first+int64(*count) {
+			searched = append(searched, result)
+		}
+	}
+	sort.Slice(searched, func(i, j int) bool { return searched[i].Seed < searched[j].Seed })
+	report, err := os.Create(*reportPath)
+	util.Check(err)
+	err = writeReport(report, p, searched, *top)
+	util.Check(err)
+	err = report.Close()
+	util.Check(err)
+	fmt.Println("File", *reportPath, "output done!")
+}
+
+// endState starts a new line at the end of the state file, if an interrupted search left a partly written one.
+func endState(state *os.File) {
+	info, err := state.Stat()
+	util.Check(err)
+	if info.Size() == 0 {
+		return
+	}
+	last := make([]byte, 1)
+	_, err = state.ReadAt(last, info.Size()-1)
+	util.Check(err)
+	if last[0] != '\n' {
+		_, err = state.Write([]byte("\n"))
+		util.Check(err)
+	}
+}
+
+// readState reads the results saved by previous searches. A partly written last line,
+// left by a search that was interrupted, is ignored.
+func readState(path string) []soupResult {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	util.Check(err)
+	defer file.Close()
+	var results []soupResult
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var result soupResult
+		if json.Unmarshal(scanner.Bytes(), &result) == nil {
+			results = append(results, result)
+		}
+	}
+	util.Check(scanner.Err())
+	return results
+}
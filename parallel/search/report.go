@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// rareSoups is the number of soups an object may appear in to be reported as rare.
+const rareSoups = 3
+
+// writeReport writes the object counts, lifespans and the most interesting seeds of the results.
+func writeReport(w io.Writer, p gol.Params, results []soupResult, top int) error {
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintf(table, "%v soups of %vx%v at density %v on a %vx%v torus, up to %v turns\n\n",
+		len(results), p.Soup.Size, p.Soup.Size, p.Soup.Density, p.ImageWidth, p.ImageHeight, p.Turns)
+
+	// 寿命统计
+	//lifespans
+	stabilised, total := 0, 0
+	periods := map[int]int{}
+	for _, result := range results {
+		if result.Lifespan >= 0 {
+			stabilised++
+			total += result.Lifespan
+			periods[result.Period]++
+		}
+	}
+	if stabilised > 0 {
+		_, _ = fmt.Fprintf(table, "%v soups stabilised, after %.1f turns on average\n", stabilised, float64(total)/float64(stabilised))
+	}
+	_, _ = fmt.Fprintf(table, "%v soups did not stabilise within %v turns\n\n", len(results)-stabilised, p.Turns)
+
+	// 物体统计
+	//objects
+	counts := map[string]int{}
+	soups := map[string][]int64{}
+	for _, result := range results {
+		for name, count := range result.Objects {
+			counts[name] += count
+			soups[name] = append(soups[name], result.Seed)
+		}
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	_, _ = fmt.Fprintln(table, "object\tcount\tsoups")
+	for _, name := range names {
+		_, _ = fmt.Fprintf(table, "%v\t%v\t%v\n", name, counts[name], len(soups[name]))
+	}
+
+	// 最有趣的种子：寿命最长的，以及含有稀有物体的
+	//the most interesting seeds: the longest lived, and the ones with rare objects
+	longest := append([]soupResult(nil), results...)
+	sort.Slice(longest, func(i, j int) bool {
+		if lifespan(longest[i], p) != lifespan(longest[j], p) {
+			return lifespan(longest[i], p) > lifespan(longest[j], p)
+		}
+		return longest[i].Seed < longest[j].Seed
+	})
+	if len(longest) > top {
+		longest = longest[:top]
+	}
+	_, _ = fmt.Fprintln(table, "\nlongest lived seeds")
+	_, _ = fmt.Fprintln(table, "seed\tlifespan\tperiod\tpopulation")
+	for _, result := range longest {
+		life := fmt.Sprint(result.Lifespan)
+		if result.Lifespan < 0 {
+			life = fmt.Sprintf(">%v", p.Turns)
+		}
+		_, _ = fmt.Fprintf(table, "%v\t%v\t%v\t%v\n", result.Seed, life, result.Period, result.Population)
+	}
+
+	_, _ = fmt.Fprintln(table, "\nrare objects")
+	_, _ = fmt.Fprintln(table, "object\tseeds")
+	for _, name := range names {
+		if len(soups[name]) <= rareSoups {
+			_, _ = fmt.Fprintf(table, "%v\t%v\n", name, soups[name])
+		}
+	}
+	return table.Flush()
+}
+
+// lifespan orders results by how long they lived, counting soups that never stabilised as the longest.
+func lifespan(result soupResult, p gol.Params) int {
+	if result.Lifespan < 0 {
+		return p.Turns + 1
+	}
+	return result.Lifespan
+}
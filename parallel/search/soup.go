@@ -0,0 +1,46 @@
+package main
+
+import (
+	"uk.ac.bris.cs/gameoflife/analysis"
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// soupResult is the outcome of running a single soup, saved as one line of the state file.
+type soupResult struct {
+	Seed int64 `json:"seed"`
+	// Lifespan is the turn the world became periodic, or -1 when it did not within the turn limit.
+	Lifespan   int            `json:"lifespan"`
+	Period     int            `json:"period"`
+	Population int            `json:"population"`
+	Objects    map[string]int `json:"objects"`
+}
+
+// runSoup runs the soup with the given seed until it becomes periodic or p.Turns turns have completed,
+// then takes a census of the world.
+func runSoup(p gol.Params, seed int64) soupResult {
+	p.Soup.Seed = seed
+	p.FlipDelivery = gol.BatchedFlips
+	events := make(chan gol.Event, 1000)
+	keyPresses := make(chan rune, 1)
+	go gol.Run(p, events, keyPresses)
+
+	detector := gol.NewCycleDetector(p)
+	for event := range events {
+		if detector.Handle(event) {
+			// 世界已经是周期性的，不需要继续运行
+			//the world is periodic, so there is no need to keep running
+			keyPresses <- 'q'
+		}
+	}
+
+	census := analysis.TakeCensus(detector.Frame(), p.ImageWidth, p.ImageHeight)
+	result := soupResult{Seed: seed, Lifespan: -1, Objects: census.Counts}
+	if detector.Found() {
+		result.Lifespan = detector.Start()
+		result.Period = detector.Period()
+	}
+	for _, object := range census.Objects {
+		result.Population += len(object.Cells)
+	}
+	return result
+}
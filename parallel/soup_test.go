@@ -0,0 +1,125 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestSoup tests that random worlds are reproducible from their seed, fill only the centred region
+// and are saved alongside the outputs.
+func TestSoup(t *testing.T) {
+	generate := func(seed int64) ([]util.Cell, gol.Params) {
+		p := gol.Params{
+			Turns:       0,
+			Threads:     4,
+			ImageWidth:  64,
+			ImageHeight: 64,
+			OutputDir:   t.TempDir(),
+			Checkpoint:  filepath.Join(t.TempDir(), "soup.checkpoint"),
+			Soup:        gol.Soup{Density: 0.5, Seed: seed, Size: 16},
+		}
+		events := make(chan gol.Event)
+		go gol.Run(p, events, nil)
+		var alive []util.Cell
+		flipped := false
+		for event := range events {
+			switch e := event.(type) {
+			case gol.CellFlipped:
+				flipped = true
+			case gol.ImageOutputComplete:
+				// 保存随机世界时初始细胞已经发送
+				//the initial cells are sent before the soup is saved
+				if e.CompletedTurns == 0 && !flipped {
+					t.Errorf("Expected the initial cells before %v", e.Filename)
+				}
+			case gol.FinalTurnComplete:
+				alive = e.Alive
+			}
+		}
+		return alive, p
+	}
+
+	first, p := generate(42)
+	second, _ := generate(42)
+	other, _ := generate(43)
+	zero, _ := generate(0)
+	if !sameCells(first, second) {
+		t.Fatal("The same seed generated different worlds")
+	}
+	if sameCells(first, other) || sameCells(first, zero) || len(zero) == 0 {
+		t.Fatal("Different seeds generated the same world")
+	}
+	if len(first) < 64 || len(first) > 192 {
+		t.Fatalf("Expected about half of the 256 cells to be alive, got %v", len(first))
+	}
+	for _, cell := range first {
+		if cell.X < 24 || cell.X >= 40 || cell.Y < 24 || cell.Y >= 40 {
+			t.Fatalf("Cell %v is outside the centred 16x16 soup", cell)
+		}
+	}
+
+	soup, err := ioutil.ReadFile(filepath.Join(p.OutputDir, "64x64x0-soup.pgm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := 0
+	for _, value := range soup[len(soup)-p.ImageWidth*p.ImageHeight:] {
+		if value == 255 {
+			saved++
+		}
+	}
+	if saved != len(first) {
+		t.Fatalf("Expected the saved soup to have %v alive cells, got %v", len(first), saved)
+	}
+	checkpoint, _, err := gol.CheckpointParams(p.Checkpoint)
+	if err != nil || checkpoint.Soup != p.Soup {
+		t.Fatalf("Expected the checkpoint to record soup %v, got %v (%v)", p.Soup, checkpoint.Soup, err)
+	}
+}
+
+// TestSoupGoroutines tests that running many soups, like the soup search does, leaves no goroutines behind.
+func TestSoupGoroutines(t *testing.T) {
+	run := func(seed int64) {
+		p := gol.Params{
+			Turns:         50,
+			Threads:       2,
+			ImageWidth:    32,
+			ImageHeight:   32,
+			OutputDir:     t.TempDir(),
+			Soup:          gol.Soup{Density: 0.4, Seed: seed, Size: 16},
+			FlipDelivery:  gol.BatchedFlips,
+			AliveInterval: time.Millisecond,
+		}
+		events := make(chan gol.Event, 1000)
+		keyPresses := make(chan rune, 1)
+		go gol.Run(p, events, keyPresses)
+		detector := gol.NewCycleDetector(p)
+		for event := range events {
+			if detector.Handle(event) {
+				keyPresses <- 'q'
+			}
+		}
+	}
+
+	run(0)
+	before := runtime.NumGoroutine()
+	for seed := int64(1); seed <= 20; seed++ {
+		run(seed)
+	}
+	// 线程在事件通道关闭后才退出，所以等待一会儿
+	//the goroutines exit after the events channel is closed, so wait for them a little
+	after := runtime.NumGoroutine()
+	for i := 0; i < 100 && after > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+		after = runtime.NumGoroutine()
+	}
+	if after > before {
+		t.Errorf("Expected %v goroutines after running 20 soups, got %v", before, after)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestPopulationStats tests PopulationStats events against the alive counts in check/alive,
+// reported every turn and every 10 turns.
+func TestPopulationStats(t *testing.T) {
+	for _, every := range []int{1, 10} {
+		p := gol.Params{
+			Turns:       100,
+			Threads:     8,
+			ImageWidth:  64,
+			ImageHeight: 64,
+			OutputDir:   t.TempDir(),
+			StatsEvery:  every,
+			StatsBands:  5,
+		}
+		alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+		t.Run(fmt.Sprintf("every-%d", every), func(t *testing.T) {
+			events := make(chan gol.Event)
+			go gol.Run(p, events, nil)
+			reports := 0
+			var last gol.PopulationStats
+			for event := range events {
+				switch e := event.(type) {
+				case gol.PopulationStats:
+					reports++
+					if e.CompletedTurns%every != 0 || e.Alive != alive[e.CompletedTurns] {
+						t.Fatalf("At turn %v expected %v alive cells, got %v", e.CompletedTurns, alive[e.CompletedTurns], e.Alive)
+					}
+					if e.Births-e.Deaths != e.Change || (reports > 1 && e.Alive-last.Alive != e.Change) {
+						t.Fatalf("At turn %v births %v, deaths %v and change %v do not add up",
+							e.CompletedTurns, e.Births, e.Deaths, e.Change)
+					}
+					total := 0.0
+					for band, density := range e.BandDensity {
+						rows := p.ImageHeight / p.StatsBands
+						if band < p.ImageHeight%p.StatsBands {
+							rows++
+						}
+						total += density * float64(rows*p.ImageWidth)
+					}
+					if len(e.BandDensity) != p.StatsBands || math.Abs(total-float64(e.Alive)) > 0.5 {
+						t.Fatalf("At turn %v band densities %v do not add up to %v alive cells", e.CompletedTurns, e.BandDensity, e.Alive)
+					}
+					last = e
+				case gol.FinalTurnComplete:
+					for _, cell := range e.Alive {
+						if !image.Pt(cell.X, cell.Y).In(last.Bounds) {
+							t.Fatalf("alive cell %v is outside the bounds %v", cell, last.Bounds)
+						}
+					}
+				}
+			}
+			if reports != p.Turns/every {
+				t.Fatalf("expected %v PopulationStats events, got %v", p.Turns/every, reports)
+			}
+		})
+	}
+}
+
+// TestStatsCSV tests that the csv written from PopulationStats events matches the tables in check/alive.
+func TestStatsCSV(t *testing.T) {
+	p := gol.Params{
+		Turns:       100,
+		Threads:     8,
+		ImageWidth:  64,
+		ImageHeight: 64,
+		OutputDir:   t.TempDir(),
+		StatsEvery:  1,
+	}
+	alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	var buffer bytes.Buffer
+	err := gol.WriteStatsCSV(events, &buffer)
+	util.Check(err)
+
+	table, err := csv.NewReader(&buffer).ReadAll()
+	util.Check(err)
+	if len(table) != p.Turns+1 {
+		t.Fatalf("expected a header and %v rows, got %v rows", p.Turns, len(table))
+	}
+	for _, row := range table[1:] {
+		turn, _ := strconv.Atoi(row[0])
+		count, _ := strconv.Atoi(row[1])
+		if alive[turn] != count {
+			t.Fatalf("At turn %v expected %v alive cells, got %v", turn, alive[turn], count)
+		}
+	}
+}
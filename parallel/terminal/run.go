@@ -0,0 +1,150 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// frameInterval is the shortest time between two frames drawn in the terminal.
+const frameInterval = 50 * time.Millisecond
+
+// Run draws the events of a run in the terminal until the run finishes, like sdl.Run.
+// The keys p, s, q, k and c are sent to keyPresses and the arrow keys move the view.
+// When the terminal cannot be put in raw mode, the board is still drawn but keys are not read.
+func Run(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune) {
+	tty, err := os.Open("/dev/tty")
+	if err == nil {
+		defer tty.Close()
+	}
+	restore := rawMode(tty)
+	defer restore()
+
+	columns, rows := size(tty)
+	screen := NewScreen(p.ImageWidth, p.ImageHeight, columns, rows)
+	// Use the alternate screen and hide the cursor, so the terminal is left as it was.
+	fmt.Print("\x1b[?1049h\x1b[?25l\x1b[2J")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	pans := make(chan [2]int, 10)
+	if tty != nil {
+		go readKeys(tty, keyPresses, pans)
+	}
+
+	frames := time.NewTicker(frameInterval)
+	defer frames.Stop()
+	resizes := time.NewTicker(time.Second)
+	defer resizes.Stop()
+	dirty := true
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if _, final := event.(gol.FinalTurnComplete); final {
+				screen.Handle(event)
+				screen.Draw(os.Stdout)
+				return
+			}
+			if screen.Handle(event) {
+				dirty = true
+			}
+		case pan := <-pans:
+			screen.PanPage(pan[0], pan[1])
+			dirty = true
+		case <-resizes.C:
+			if c, r := size(tty); c != screen.columns || r != screen.rows {
+				screen.Resize(c, r)
+				fmt.Print("\x1b[2J")
+				dirty = true
+			}
+		case <-frames.C:
+			if dirty {
+				screen.Draw(os.Stdout)
+				dirty = false
+			}
+		}
+	}
+}
+
+// readKeys sends the keys pressed in the terminal to keyPresses, and the arrow keys to pans.
+func readKeys(tty *os.File, keyPresses chan<- rune, pans chan<- [2]int) {
+	in := bufio.NewReader(tty)
+	for {
+		b, err := in.ReadByte()
+		if err != nil {
+			return
+		}
+		switch b {
+		case 'p', 's', 'q', 'k', 'c':
+			keyPresses <- rune(b)
+		case 3:
+			// Ctrl-C does not interrupt the program in raw mode, so it quits the run instead.
+			keyPresses <- 'q'
+		case 0x1b:
+			// The arrow keys send ESC [ followed by A, B, C or D.
+			if next, _ := in.ReadByte(); next != '[' {
+				continue
+			}
+			arrow, _ := in.ReadByte()
+			switch arrow {
+			case 'A':
+				pans <- [2]int{0, -1}
+			case 'B':
+				pans <- [2]int{0, 1}
+			case 'C':
+				pans <- [2]int{1, 0}
+			case 'D':
+				pans <- [2]int{-1, 0}
+			}
+		}
+	}
+}
+
+// rawMode puts the terminal in raw mode with stty, so keys are read as soon as they are pressed.
+// It returns a function restoring the previous mode.
+func rawMode(tty *os.File) func() {
+	if tty == nil {
+		return func() {}
+	}
+	saved, err := stty(tty, "-g")
+	if err != nil {
+		return func() {}
+	}
+	if _, err := stty(tty, "raw", "-echo"); err != nil {
+		return func() {}
+	}
+	return func() {
+		stty(tty, strings.TrimSpace(saved))
+	}
+}
+
+// size returns the number of columns and rows of the terminal, 80 by 24 when it cannot be found.
+func size(tty *os.File) (int, int) {
+	columns, rows := 80, 24
+	if tty == nil {
+		return columns, rows
+	}
+	out, err := stty(tty, "size")
+	if err != nil {
+		return columns, rows
+	}
+	var c, r int
+	if _, err := fmt.Sscan(out, &r, &c); err != nil || c == 0 || r == 0 {
+		return columns, rows
+	}
+	return c, r
+}
+
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return string(out), err
+}
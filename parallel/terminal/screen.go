@@ -0,0 +1,171 @@
+// Package terminal draws a run in an ANSI terminal with Unicode half-blocks, as an alternative to
+// the SDL window on machines without a display.
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// Half-blocks draw two cells, one above the other, in a single character.
+const (
+	upperHalf = "▀"
+	lowerHalf = "▄"
+	fullBlock = "█"
+)
+
+// Screen is the part of the board shown in a terminal, with a status line below it.
+// Every row of the terminal but the last shows two rows of the board.
+type Screen struct {
+	width, height int
+	cells         []bool
+	population    int
+	columns, rows int
+	// view is the top left cell of the board shown in the terminal.
+	view    image.Point
+	turn    int
+	state   string
+	message string
+}
+
+// NewScreen creates a screen for a board of the given size, in a terminal of columns by rows characters.
+// The view starts in the centre of the board.
+func NewScreen(width, height, columns, rows int) *Screen {
+	s := &Screen{width: width, height: height, cells: make([]bool, width*height), state: "Executing"}
+	s.Resize(columns, rows)
+	s.Pan((width-s.columns)/2, (height-2*(s.rows-1))/2)
+	return s
+}
+
+// Resize changes the size of the terminal, keeping the view on the board.
+func (s *Screen) Resize(columns, rows int) {
+	if rows < 2 {
+		rows = 2
+	}
+	if columns < 1 {
+		columns = 1
+	}
+	s.columns, s.rows = columns, rows
+	s.Pan(0, 0)
+}
+
+// Pan moves the view by dx, dy cells, stopping at the edges of the board.
+func (s *Screen) Pan(dx, dy int) {
+	s.view.X = clamp(s.view.X+dx, s.width-s.columns)
+	s.view.Y = clamp(s.view.Y+dy, s.height-2*(s.rows-1))
+}
+
+// PanPage moves the view by a quarter of its size in the direction of dx, dy.
+func (s *Screen) PanPage(dx, dy int) {
+	s.Pan(dx*maxInt(s.columns/4, 1), dy*maxInt(s.rows/2, 1))
+}
+
+// Handle updates the screen with an event. It reports true when the screen should be drawn again.
+func (s *Screen) Handle(event gol.Event) bool {
+	switch e := event.(type) {
+	case gol.CellFlipped:
+		s.flip(e.Cell.X, e.Cell.Y)
+		return false
+	case gol.CellsFlipped:
+		for _, cell := range e.Cells {
+			s.flip(cell.X, cell.Y)
+		}
+		return false
+	case gol.TurnComplete:
+		s.turn = e.CompletedTurns
+	case gol.EditComplete:
+	case gol.StateChange:
+		s.turn = e.CompletedTurns
+		switch e.NewState {
+		case gol.Paused:
+			s.state = "Paused"
+		case gol.Executing:
+			s.state = "Executing"
+		case gol.Quitting:
+			s.state = "Quitting"
+		}
+	default:
+		if event.String() == "" {
+			return false
+		}
+		s.message = event.String()
+	}
+	return true
+}
+
+func (s *Screen) flip(x, y int) {
+	index := y*s.width + x
+	s.cells[index] = !s.cells[index]
+	if s.cells[index] {
+		s.population++
+	} else {
+		s.population--
+	}
+}
+
+func (s *Screen) alive(x, y int) bool {
+	return x >= 0 && x < s.width && y >= 0 && y < s.height && s.cells[y*s.width+x]
+}
+
+// Status returns the text of the status line.
+func (s *Screen) Status() string {
+	status := fmt.Sprintf("Turn %v  Alive %v  %v  View %v,%v of %vx%v",
+		s.turn, s.population, s.state, s.view.X, s.view.Y, s.width, s.height)
+	if s.message != "" {
+		status += "  " + s.message
+	}
+	return status
+}
+
+// Draw writes the screen to w from the top left corner of the terminal.
+func (s *Screen) Draw(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	out.WriteString("\x1b[H")
+	for row := 0; row < s.rows-1; row++ {
+		y := s.view.Y + 2*row
+		for column := 0; column < s.columns; column++ {
+			x := s.view.X + column
+			top, bottom := s.alive(x, y), s.alive(x, y+1)
+			switch {
+			case top && bottom:
+				out.WriteString(fullBlock)
+			case top:
+				out.WriteString(upperHalf)
+			case bottom:
+				out.WriteString(lowerHalf)
+			default:
+				out.WriteByte(' ')
+			}
+		}
+		// In raw mode a new line does not return to the start of the line.
+		out.WriteString("\x1b[K\r\n")
+	}
+	status := s.Status()
+	if len(status) > s.columns {
+		status = status[:s.columns]
+	}
+	out.WriteString("\x1b[7m" + status + strings.Repeat(" ", s.columns-len(status)) + "\x1b[0m")
+	return out.Flush()
+}
+
+func clamp(value, max int) int {
+	if value > max {
+		value = max
+	}
+	if value < 0 {
+		value = 0
+	}
+	return value
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/terminal"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestTerminal tests that the terminal screen draws two rows of cells in every line with half-blocks.
+func TestTerminal(t *testing.T) {
+	screen := terminal.NewScreen(4, 4, 4, 3)
+	screen.Handle(gol.CellsFlipped{CompletedTurns: 0, Cells: pictureCells([]string{
+		".O..",
+		"..O.",
+		"OOO.",
+	}, 0, 0)})
+	if !screen.Handle(gol.TurnComplete{CompletedTurns: 1}) {
+		t.Fatal("Expected the screen to be drawn after a turn")
+	}
+	var out bytes.Buffer
+	err := screen.Draw(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimPrefix(out.String(), "\x1b[H"), "\x1b[K\r\n")
+	if len(lines) != 3 || lines[0] != " ▀▄ " || lines[1] != "▀▀▀ " {
+		t.Fatalf("Unexpected screen %q", out.String())
+	}
+	if !strings.HasPrefix(screen.Status(), "Turn 1  Alive 5") || lines[2] != "\x1b[7mTurn\x1b[0m" {
+		t.Errorf("Unexpected status line %q", screen.Status())
+	}
+
+	// 视图停在世界的边缘
+	//the view stops at the edges of the world
+	screen = terminal.NewScreen(16, 16, 4, 3)
+	screen.Handle(gol.CellFlipped{Cell: util.Cell{X: 15, Y: 15}})
+	screen.PanPage(10, 10)
+	out.Reset()
+	screen.Draw(&out)
+	lines = strings.Split(strings.TrimPrefix(out.String(), "\x1b[H"), "\x1b[K\r\n")
+	if lines[1] != "   ▄" || !strings.Contains(screen.Status(), "View 12,12") {
+		t.Errorf("Unexpected screen after panning %q", out.String())
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestObjectsMoved follows a glider into a block and an LWSS around the torus.
+func TestObjectsMoved(t *testing.T) {
+	p := gol.Params{
+		Turns:       200,
+		Threads:     8,
+		ImageWidth:  64,
+		ImageHeight: 64,
+		InputDir:    t.TempDir(),
+		OutputDir:   t.TempDir(),
+		TrackEvery:  1,
+	}
+	frame := make([]byte, p.ImageWidth*p.ImageHeight)
+	draw := func(x, y int, picture ...string) {
+		for _, cell := range pictureCells(picture, x, y) {
+			frame[cell.Y*p.ImageWidth+cell.X] = 255
+		}
+	}
+	draw(5, 5, ".O.", "..O", "OOO")
+	draw(30, 30, "OO", "OO")
+	draw(10, 45, ".O..O", "O....", "O...O", "OOOO.")
+	err := ioutil.WriteFile(filepath.Join(p.InputDir, "64x64.pgm"), append([]byte("P5\n64 64\n255\n"), frame...), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	hub := gol.NewHub(events)
+	moved := hub.Subscribe(gol.EventTypes(gol.ObjectsMoved{}), gol.NoFlips, 0, gol.Unbounded)
+	summary := hub.Subscribe(gol.EventTypes(gol.ObjectsMoved{}), gol.NoFlips, 0, gol.Unbounded)
+	var report bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- gol.WriteTrackSummary(summary, &report)
+	}()
+	go hub.Run()
+
+	collided := false
+	reports := 0
+	for event := range moved {
+		e := event.(gol.ObjectsMoved)
+		reports++
+		for _, collision := range e.Collisions {
+			if collision.Name != "glider" || len(collision.Debris) == 0 || e.CompletedTurns < 80 || e.CompletedTurns > 95 {
+				t.Fatalf("Unexpected collision %v at turn %v", collision, e.CompletedTurns)
+			}
+			collided = true
+		}
+		for _, move := range e.Moves {
+			if move.Name == "LWSS" && e.CompletedTurns%4 == 0 && (move.Velocity.X != -0.5 || move.Velocity.Y != 0 || move.Heading != "W") {
+				t.Fatalf("Expected the LWSS to move west at c/2, got %v at turn %v", move, e.CompletedTurns)
+			}
+		}
+	}
+	if reports != p.Turns || !collided {
+		t.Fatalf("Expected %v ObjectsMoved events and a collision, got %v events", p.Turns, reports)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "glider") || !strings.Contains(lines[1], "hit") ||
+		!strings.Contains(lines[2], "LWSS") || !strings.Contains(lines[2], "moving") {
+		t.Fatalf("Unexpected summary:\n%v", report.String())
+	}
+}
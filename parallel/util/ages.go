@@ -0,0 +1,47 @@
+package util
+
+// CellAges follows how many turns every cell has been alive, or dead since it was last alive,
+// from the cells flipped in every turn.
+type CellAges struct {
+	width, height int
+	alive         []bool
+	// changed is the turn every cell last changed state, -1 for cells that were never alive.
+	changed []int
+	turn    int
+}
+
+// NewCellAges creates the ages of a board of the given size with every cell dead.
+func NewCellAges(width, height int) *CellAges {
+	changed := make([]int, width*height)
+	for i := range changed {
+		changed[i] = -1
+	}
+	return &CellAges{width: width, height: height, alive: make([]bool, width*height), changed: changed}
+}
+
+// Flip changes the state of the cell at x, y in the current turn.
+func (ages *CellAges) Flip(x, y int) {
+	i := y*ages.width + x
+	ages.alive[i] = !ages.alive[i]
+	ages.changed[i] = ages.turn
+}
+
+// CompleteTurn ends the current turn, making every cell a turn older.
+func (ages *CellAges) CompleteTurn() {
+	ages.turn++
+}
+
+// Alive reports whether the cell at x, y is alive.
+func (ages *CellAges) Alive(x, y int) bool {
+	return ages.alive[y*ages.width+x]
+}
+
+// State returns whether the cell at x, y is alive and for how many turns it has been alive or dead.
+// A cell that changed state in the current turn has age 0. The age of a cell that was never alive is -1.
+func (ages *CellAges) State(x, y int) (bool, int) {
+	i := y*ages.width + x
+	if ages.changed[i] < 0 {
+		return false, -1
+	}
+	return ages.alive[i], ages.turn - ages.changed[i]
+}
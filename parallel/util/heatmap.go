@@ -0,0 +1,54 @@
+package util
+
+import (
+	"image"
+	"image/color"
+)
+
+// Gradient maps values from 0 to 1 to colours, blending between evenly spaced stops.
+type Gradient []color.RGBA
+
+// Gradients are the built-in gradients, selectable by name.
+var Gradients = map[string]Gradient{
+	"grey": {{0x00, 0x00, 0x00, 0xFF}, {0xFF, 0xFF, 0xFF, 0xFF}},
+	"heat": {
+		{0x00, 0x00, 0x00, 0xFF},
+		{0x30, 0x00, 0x90, 0xFF},
+		{0xD0, 0x00, 0x30, 0xFF},
+		{0xFF, 0xA0, 0x00, 0xFF},
+		{0xFF, 0xFF, 0xFF, 0xFF},
+	},
+}
+
+// At returns the colour of value, which is clamped between 0 and 1.
+func (gradient Gradient) At(value float64) color.RGBA {
+	if value <= 0 {
+		return gradient[0]
+	}
+	if value >= 1 {
+		return gradient[len(gradient)-1]
+	}
+	position := value * float64(len(gradient)-1)
+	stop := int(position)
+	fraction := position - float64(stop)
+	from, to := gradient[stop], gradient[stop+1]
+	blend := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*fraction + 0.5)
+	}
+	return color.RGBA{blend(from.R, to.R), blend(from.G, to.G), blend(from.B, to.B), 0xFF}
+}
+
+// HeatmapImage draws a row-major map of values from 0 to 1 as an image coloured by gradient,
+// with every cell drawn as a scale x scale square.
+func HeatmapImage(values []float64, width, height, scale int, gradient Gradient) *image.RGBA {
+	if scale < 1 {
+		scale = 1
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width*scale, height*scale))
+	for y := 0; y < height*scale; y++ {
+		for x := 0; x < width*scale; x++ {
+			img.SetRGBA(x, y, gradient.At(values[(y/scale)*width+x/scale]))
+		}
+	}
+	return img
+}
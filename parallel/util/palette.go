@@ -0,0 +1,160 @@
+package util
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Palette holds the colours used to draw dead and alive cells.
+// When Old is set, alive cells are coloured by age instead of Alive: cells born in the last turn are Newborn,
+// and older cells fade from Young to Old over OldAge turns. When Trail is set, cells that died in the last
+// turn are Trail and fade to Dead over TrailTurns turns. See CellAges.
+type Palette struct {
+	Dead, Alive         color.RGBA
+	Newborn, Young, Old color.RGBA
+	Trail               color.RGBA
+}
+
+const (
+	// OldAge is the age in turns from which alive cells are drawn with the Old colour of a Palette.
+	OldAge = 32
+	// TrailTurns is the number of turns the Trail colour of a Palette takes to fade to Dead.
+	TrailTurns = 8
+)
+
+// Palettes are the built-in palettes, selectable by name.
+var Palettes = map[string]Palette{
+	"mono":     {Dead: color.RGBA{0x00, 0x00, 0x00, 0xFF}, Alive: color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}},
+	"inverted": {Dead: color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}, Alive: color.RGBA{0x00, 0x00, 0x00, 0xFF}},
+	"green":    {Dead: color.RGBA{0x0B, 0x1A, 0x0B, 0xFF}, Alive: color.RGBA{0x39, 0xFF, 0x14, 0xFF}},
+	"amber":    {Dead: color.RGBA{0x1A, 0x12, 0x00, 0xFF}, Alive: color.RGBA{0xFF, 0xB0, 0x00, 0xFF}},
+	"fire": {
+		Dead:    color.RGBA{0x10, 0x08, 0x08, 0xFF},
+		Alive:   color.RGBA{0xFF, 0xA0, 0x00, 0xFF},
+		Newborn: color.RGBA{0xFF, 0xFF, 0xC0, 0xFF},
+		Young:   color.RGBA{0xFF, 0xC0, 0x00, 0xFF},
+		Old:     color.RGBA{0xB0, 0x10, 0x00, 0xFF},
+		Trail:   color.RGBA{0x60, 0x20, 0x10, 0xFF},
+	},
+	"ocean": {
+		Dead:    color.RGBA{0x00, 0x10, 0x20, 0xFF},
+		Alive:   color.RGBA{0x40, 0xC0, 0xFF, 0xFF},
+		Newborn: color.RGBA{0xE0, 0xFF, 0xFF, 0xFF},
+		Young:   color.RGBA{0x40, 0xE0, 0xD0, 0xFF},
+		Old:     color.RGBA{0x10, 0x40, 0xC0, 0xFF},
+		Trail:   color.RGBA{0x10, 0x38, 0x60, 0xFF},
+	},
+}
+
+// DefaultPalette matches the pgm images: alive cells are white and dead cells are black.
+var DefaultPalette = Palettes["mono"]
+
+// ParsePalette returns the built-in palette with the given name, or reads a palette from its colours
+// as dead,alive, dead,alive,newborn,young,old or dead,alive,newborn,young,old,trail in hex, like #39FF14.
+func ParsePalette(value string) (Palette, error) {
+	if palette, ok := Palettes[value]; ok {
+		return palette, nil
+	}
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 && len(parts) != 5 && len(parts) != 6 {
+		return Palette{}, fmt.Errorf("unknown palette %v", value)
+	}
+	colours := make([]color.RGBA, len(parts))
+	for i, part := range parts {
+		part = strings.TrimPrefix(strings.TrimSpace(part), "#")
+		rgb, err := strconv.ParseUint(part, 16, 32)
+		if err != nil || len(part) != 6 {
+			return Palette{}, fmt.Errorf("palette colour %v should be 6 hex digits like #39FF14", parts[i])
+		}
+		colours[i] = color.RGBA{uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), 0xFF}
+	}
+	palette := Palette{Dead: colours[0], Alive: colours[1]}
+	if len(colours) >= 5 {
+		palette.Newborn, palette.Young, palette.Old = colours[2], colours[3], colours[4]
+	}
+	if len(colours) == 6 {
+		palette.Trail = colours[5]
+	}
+	return palette, nil
+}
+
+// Aged reports whether cells are coloured by age.
+func (palette Palette) Aged() bool {
+	return palette.Old != (color.RGBA{}) || palette.Trail != (color.RGBA{})
+}
+
+// Colours returns every colour of the palette, in the order of the indices returned by Index.
+func (palette Palette) Colours() color.Palette {
+	colours := color.Palette{palette.Dead, palette.Alive}
+	if !palette.Aged() {
+		return colours
+	}
+	colours = append(colours, palette.Newborn)
+	for age := 2; age <= OldAge; age++ {
+		colours = append(colours, Gradient{palette.Young, palette.Old}.At(float64(age-2)/float64(OldAge-2)))
+	}
+	for age := 1; age < TrailTurns; age++ {
+		colours = append(colours, Gradient{palette.Trail, palette.Dead}.At(float64(age-1)/float64(TrailTurns-1)))
+	}
+	return colours
+}
+
+// Index returns the index in Colours of a cell that has been alive or dead for age turns.
+// The age of a dead cell that was never alive is negative.
+func (palette Palette) Index(alive bool, age int) uint8 {
+	switch {
+	case alive && palette.Old == (color.RGBA{}):
+		return 1
+	case alive && age <= 1:
+		return 2
+	case alive && age >= OldAge:
+		return OldAge + 1
+	case alive:
+		return uint8(age + 1)
+	case palette.Trail == (color.RGBA{}) || age < 0 || age >= TrailTurns:
+		return 0
+	case age <= 1:
+		return OldAge + 2
+	default:
+		return uint8(OldAge + 1 + age)
+	}
+}
+
+// FrameImage draws a row-major frame of cells (0 dead, 255 alive) as an image,
+// with every cell drawn as a scale x scale square. Cells are not coloured by age, see AgesImage.
+func FrameImage(frame []uint8, width, height, scale int, palette Palette) *image.Paletted {
+	if scale < 1 {
+		scale = 1
+	}
+	img := image.NewPaletted(
+		image.Rect(0, 0, width*scale, height*scale),
+		palette.Colours(),
+	)
+	for y := 0; y < height*scale; y++ {
+		row := frame[(y/scale)*width : (y/scale+1)*width]
+		for x := 0; x < width*scale; x++ {
+			if row[x/scale] != 0 {
+				img.Pix[y*img.Stride+x] = 1
+			}
+		}
+	}
+	return img
+}
+
+// AgesImage draws the cells followed by ages as an image coloured by age,
+// with every cell drawn as a scale x scale square.
+func AgesImage(ages *CellAges, scale int, palette Palette) *image.Paletted {
+	if scale < 1 {
+		scale = 1
+	}
+	img := image.NewPaletted(image.Rect(0, 0, ages.width*scale, ages.height*scale), palette.Colours())
+	for y := 0; y < ages.height*scale; y++ {
+		for x := 0; x < ages.width*scale; x++ {
+			img.Pix[y*img.Stride+x] = palette.Index(ages.State(x/scale, y/scale))
+		}
+	}
+	return img
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"image"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/sdl"
+)
+
+// TestView tests the zoom, pan and fit modes of the SDL window.
+func TestView(t *testing.T) {
+	expectRects := func(view *sdl.View, src, dst image.Rectangle) {
+		t.Helper()
+		gotSrc, gotDst := view.Rects()
+		if gotSrc != src || gotDst != dst {
+			t.Errorf("Expected %v drawn at %v, got %v drawn at %v", src, dst, gotSrc, gotDst)
+		}
+	}
+
+	// 小的世界被放大，缩小后居中显示
+	//small boards are zoomed in, and centred when zoomed out
+	small := sdl.NewView(16, 16)
+	if width, height := small.WindowSize(); width != 512 || height != 512 || small.Zoom() != 32 {
+		t.Fatalf("Expected a 512x512 window at zoom 32, got %vx%v at zoom %v", width, height, small.Zoom())
+	}
+	small.ZoomBy(-1, 0, 0)
+	expectRects(small, image.Rect(0, 0, 16, 16), image.Rect(128, 128, 384, 384))
+	if x, y := small.Cell(130, 160); int(x) != 0 || int(y) != 2 {
+		t.Errorf("Expected cell 0,2 under pixel 130,160, got %v,%v", x, y)
+	}
+
+	// 大的世界只显示中间的部分
+	//only the middle of large boards is shown
+	large := sdl.NewView(5000, 5000)
+	expectRects(large, image.Rect(1988, 1988, 3012, 3012), image.Rect(0, 0, 1024, 1024))
+	large.ToggleFit()
+	expectRects(large, image.Rect(0, 0, 5000, 5000), image.Rect(0, 0, 1024, 1024))
+	large.ZoomBy(1, 0, 0)
+	if large.Fit() || large.Zoom() != 2 {
+		t.Fatalf("Expected zoom 2 after zooming in from fit mode, got %v", large.Zoom())
+	}
+	expectRects(large, image.Rect(0, 0, 512, 512), image.Rect(0, 0, 1024, 1024))
+	large.Pan(100, 0)
+	expectRects(large, image.Rect(0, 0, 512, 512), image.Rect(0, 0, 1024, 1024))
+	large.Pan(-100, -50)
+	expectRects(large, image.Rect(50, 25, 562, 537), image.Rect(0, 0, 1024, 1024))
+}